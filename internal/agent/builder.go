@@ -30,7 +30,7 @@ func (a *BuilderAgent) Execute(ctx *OrchContext) error {
 
 	outputs := make([]string, 0)
 	for _, name := range tasks {
-		if _, err := taskflow.BuildRun(name, deps.cfg, deps.logger, deps.stateDir, deps.writer); err != nil {
+		if _, err := taskflow.BuildRun(name, deps.cfg, deps.logger, deps.stateDir, deps.writer, taskflow.BuildOptions{}); err != nil {
 			return err
 		}
 
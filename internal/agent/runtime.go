@@ -9,18 +9,18 @@ import (
 )
 
 const (
-	ctxKeyConfig   = "config"
-	ctxKeyLogger   = "logger"
-	ctxKeyStateDir = "state.dir"
-	ctxKeyWriter   = "writer"
-	ctxKeyGoal     = "goal"
-	ctxKeyTaskName = "task.name"
-	ctxKeyPlanGoal = "plan.goal"
-	ctxKeyPlanTasks = "plan.tasks"
+	ctxKeyConfig       = "config"
+	ctxKeyLogger       = "logger"
+	ctxKeyStateDir     = "state.dir"
+	ctxKeyWriter       = "writer"
+	ctxKeyGoal         = "goal"
+	ctxKeyTaskName     = "task.name"
+	ctxKeyPlanGoal     = "plan.goal"
+	ctxKeyPlanTasks    = "plan.tasks"
 	ctxKeyBuildOutputs = "build.outputs"
 	ctxKeyReviewStatus = "review.status"
 	ctxKeyReviewReport = "review.report"
-	ctxKeyDocPaths = "doc.paths"
+	ctxKeyDocPaths     = "doc.paths"
 )
 
 type deps struct {
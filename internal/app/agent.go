@@ -4,7 +4,6 @@ import (
 	"errors"
 	"fmt"
 	"io"
-	"os"
 	"strings"
 
 	"orchastration/internal/agent"
@@ -12,7 +11,7 @@ import (
 	"orchastration/internal/logging"
 )
 
-func runAgent(args []string, _ config.Config, _ *logging.Logger, _ string) (int, error) {
+func runAgent(args []string, _ config.Config, _ *logging.Logger, _ string, out output) (int, error) {
 	if len(args) == 0 {
 		return 2, errors.New("agent requires a subcommand")
 	}
@@ -20,14 +19,36 @@ func runAgent(args []string, _ config.Config, _ *logging.Logger, _ string) (int,
 	sub := args[0]
 	switch sub {
 	case "list":
-		return agentList(os.Stdout)
+		return agentList(out)
 	default:
 		return 2, fmt.Errorf("unknown agent subcommand: %s", sub)
 	}
 }
 
-func agentList(w io.Writer) (int, error) {
-	return agentListWith(w, agent.List())
+// agentItem is the structured output shape of agent list.
+type agentItem struct {
+	Name         string   `json:"name"`
+	Capabilities []string `json:"capabilities"`
+}
+
+func agentList(out output) (int, error) {
+	if !out.structured() {
+		return agentListWith(out.w, agent.List())
+	}
+	items := []agentItem{}
+	var rows [][]string
+	for _, info := range agent.List() {
+		caps := info.Capabilities
+		if caps == nil {
+			caps = []string{}
+		}
+		items = append(items, agentItem{Name: info.Name, Capabilities: caps})
+		rows = append(rows, []string{info.Name, orDash(strings.Join(caps, "; "))})
+	}
+	if err := out.emit(items, []string{"NAME", "CAPABILITIES"}, rows); err != nil {
+		return 2, err
+	}
+	return 0, nil
 }
 
 func agentListWith(w io.Writer, infos []agent.Info) (int, error) {
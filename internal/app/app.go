@@ -7,16 +7,49 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
 
 	"orchastration/internal/config"
+	"orchastration/internal/digest"
 	"orchastration/internal/logging"
+	"orchastration/internal/notify"
 	"orchastration/internal/platform"
+	"orchastration/internal/sandbox"
 	"orchastration/internal/version"
 )
 
 const appName = "orchastration"
 
 func Run(args []string, ver version.Info) (int, error) {
+	if len(args) > 0 && args[0] == sandbox.InitArg {
+		return sandbox.Init(args[1:]), nil
+	}
+	format, args, err := extractOutput(args)
+	if err != nil {
+		// A bad --output value still asks for structured output, so the
+		// error is a JSON object too.
+		output{}.emitError(os.Stderr, err, 2)
+		return 2, nil
+	}
+	out := output{format: format, w: os.Stdout}
+	code, err := run(args, ver, &out)
+	if err != nil && (format == outputJSON || format == outputJSONL) {
+		out.emitError(os.Stderr, err, code)
+		return code, nil
+	}
+	return code, err
+}
+
+// notifyExitWait bounds how long the process waits at exit for webhook
+// deliveries still in flight.
+const notifyExitWait = 30 * time.Second
+
+// structuredCommands are the commands that support --output other than
+// text.
+var structuredCommands = []string{"list", "status", "history", "run", "prune", "artifacts", "plan list", "plan status", "agent list", "orchestration list"}
+
+func run(args []string, ver version.Info, out *output) (int, error) {
 	if len(args) == 0 {
 		printUsage(os.Stdout)
 		return 0, nil
@@ -61,20 +94,44 @@ func Run(args []string, ver version.Info) (int, error) {
 		return 2, fmt.Errorf("load config: %w", err)
 	}
 
+	if cfg.Secrets.File == "" {
+		cfg.Secrets.File = platform.DefaultSecretsPath(appName)
+	}
+
 	logPath := platform.DefaultLogPath(appName)
-	logger, err := logging.New(cfg.Logging.Level, logPath)
+	// Structured output owns stdout, so log lines go to stderr instead.
+	var console io.Writer = os.Stdout
+	if out.structured() {
+		console = os.Stderr
+	}
+	logger, err := logging.New(cfg.Logging.Level, logPath, console)
 	if err != nil {
 		return 2, fmt.Errorf("init logger: %w", err)
 	}
+	defer func() {
+		if !notify.Wait(notifyExitWait) {
+			logger.Warn("webhook deliveries abandoned at exit", "wait_ms", notifyExitWait.Milliseconds())
+		}
+	}()
 
 	cmd := remaining[0]
+	out.command = cmd
+	if len(remaining) > 1 && !strings.HasPrefix(remaining[1], "-") && containsString([]string{"plan", "agent", "orchestration"}, cmd) {
+		out.command = cmd + " " + remaining[1]
+	}
+	if out.structured() && !containsString(structuredCommands, out.command) {
+		return 2, fmt.Errorf("--output %s is not supported by %s", out.format, out.command)
+	}
+
 	switch cmd {
 	case "hash":
 		return runHash(remaining[1:], cfg, logger)
 	case "run":
-		return runJob(remaining[1:], cfg, logger, stateDir, ver.String())
+		return runJob(remaining[1:], cfg, logger, stateDir, ver.String(), *out)
+	case "daemon":
+		return runDaemon(remaining[1:], cfg, logger, stateDir, ver.String())
 	case "plan":
-		return runPlan(remaining[1:], cfg, logger, stateDir)
+		return runPlan(remaining[1:], cfg, logger, stateDir, *out)
 	case "build":
 		return runBuild(remaining[1:], cfg, logger, stateDir)
 	case "doc":
@@ -82,13 +139,23 @@ func Run(args []string, ver version.Info) (int, error) {
 	case "git":
 		return runGit(remaining[1:], cfg, logger, stateDir)
 	case "agent":
-		return runAgent(remaining[1:], cfg, logger, stateDir)
+		return runAgent(remaining[1:], cfg, logger, stateDir, *out)
 	case "orchestration":
-		return runOrchestration(remaining[1:], cfg, logger, stateDir)
+		return runOrchestration(remaining[1:], cfg, logger, stateDir, *out)
 	case "list":
-		return listJobs(cfg)
+		return listJobs(remaining[1:], cfg, *out)
 	case "status":
-		return jobStatus(cfg, stateDir)
+		return jobStatus(cfg, logger, stateDir, *out)
+	case "history":
+		return runHistory(remaining[1:], cfg, stateDir, *out)
+	case "prune":
+		return runPrune(remaining[1:], cfg, logger, stateDir, *out)
+	case "logs":
+		return runLogs(remaining[1:], cfg, stateDir)
+	case "artifacts":
+		return runArtifacts(remaining[1:], cfg, stateDir, *out)
+	case "secret":
+		return runSecret(remaining[1:], cfg, logger)
 	default:
 		return 2, fmt.Errorf("unknown command: %s", cmd)
 	}
@@ -112,7 +179,7 @@ func runHash(args []string, cfg config.Config, logger *logging.Logger) (int, err
 		return 2, fmt.Errorf("resolve path: %w", err)
 	}
 
-	result, err := hashFile(absPath, *algo)
+	result, err := digest.File(absPath, *algo)
 	if err != nil {
 		logger.Error("hash failed", "file", absPath, "algorithm", *algo, "error", err)
 		return 2, err
@@ -126,12 +193,18 @@ func runHash(args []string, cfg config.Config, logger *logging.Logger) (int, err
 func printUsage(w io.Writer) {
 	fmt.Fprintln(w, "orchastration - cross-platform orchestration helper")
 	fmt.Fprintln(w, "\nUsage:")
-	fmt.Fprintln(w, "  orchastration [--config path] [--state-dir path] <command> [options]")
+	fmt.Fprintln(w, "  orchastration [--config path] [--state-dir path] [--output format] <command> [options]")
 	fmt.Fprintln(w, "\nCommands:")
 	fmt.Fprintln(w, "  hash   Compute file hash (useful for integrity checks)")
-	fmt.Fprintln(w, "  run    Run a configured job by name")
-	fmt.Fprintln(w, "  list   List configured jobs")
+	fmt.Fprintln(w, "  run    Run jobs by name, --all, or --tag (-j N in parallel)")
+	fmt.Fprintln(w, "  list   List configured jobs (--tag to filter)")
 	fmt.Fprintln(w, "  status Show last recorded job runs")
+	fmt.Fprintln(w, "  history Show past runs and aggregates for a job")
+	fmt.Fprintln(w, "  logs   Show captured output of a job run")
+	fmt.Fprintln(w, "  artifacts List or extract files captured by a job run")
+	fmt.Fprintln(w, "  daemon Run scheduled jobs in the foreground")
+	fmt.Fprintln(w, "  prune  Apply retention policies to the state directory")
+	fmt.Fprintln(w, "  secret Manage the encrypted secrets file (keygen, set, list, rm)")
 	fmt.Fprintln(w, "  plan   Plan workflow tasks (list, create, status)")
 	fmt.Fprintln(w, "  build  Run workflow tasks")
 	fmt.Fprintln(w, "  doc    Generate task documentation")
@@ -143,4 +216,5 @@ func printUsage(w io.Writer) {
 	fmt.Fprintln(w, "  --version    Show version")
 	fmt.Fprintln(w, "  --config     Path to config file")
 	fmt.Fprintln(w, "  --state-dir  Path to state directory")
+	fmt.Fprintln(w, "  --output     Output format of list, status, history, run, prune, artifacts, and the plan, agent, and orchestration lists (text, json, jsonl, table)")
 }
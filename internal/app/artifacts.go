@@ -0,0 +1,281 @@
+package app
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"orchastration/internal/config"
+	"orchastration/internal/digest"
+	"orchastration/internal/state"
+)
+
+// artifactItem is one artifact in the structured output of artifacts.
+type artifactItem struct {
+	RunID string `json:"run_id"`
+	state.Artifact
+	Extracted string `json:"extracted,omitempty"`
+}
+
+func runArtifacts(args []string, cfg config.Config, stateDir string, out output) (int, error) {
+	fs := flag.NewFlagSet("artifacts", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	runID := fs.String("run", "", "run ID to show (defaults to the most recent run)")
+	extract := fs.String("extract", "", "copy the artifacts into this directory")
+	positional, err := parseInterspersed(fs, args)
+	if err != nil {
+		return 2, err
+	}
+	if len(positional) == 0 {
+		return 2, errors.New("artifacts requires a job name")
+	}
+
+	jobName := positional[0]
+	if _, ok := cfg.Jobs[jobName]; !ok {
+		return 2, fmt.Errorf("unknown job: %s", jobName)
+	}
+	id, record, err := resolveArtifactRun(filepath.Join(stateDir, "runs", jobName), *runID)
+	if err != nil {
+		return 2, err
+	}
+
+	selected, err := selectArtifacts(record.Artifacts, positional[1:])
+	if err != nil {
+		return 2, err
+	}
+	if len(selected) == 0 && !out.structured() {
+		fmt.Fprintf(os.Stdout, "run=%s no artifacts\n", id)
+		return 0, nil
+	}
+
+	items := make([]artifactItem, 0, len(selected))
+	for _, artifact := range selected {
+		item := artifactItem{RunID: id, Artifact: artifact}
+		if *extract != "" {
+			if item.Extracted, err = extractArtifact(record.ArtifactsDir, artifact, *extract); err != nil {
+				return 2, err
+			}
+		}
+		items = append(items, item)
+		if out.structured() {
+			continue
+		}
+		if *extract == "" {
+			fmt.Fprintf(os.Stdout, "run=%s artifact=%s size_bytes=%d digest=%s\n", id, artifact.Path, artifact.Size, artifact.Digest)
+		} else {
+			fmt.Fprintf(os.Stdout, "run=%s artifact=%s extracted=%s\n", id, artifact.Path, item.Extracted)
+		}
+	}
+	if out.structured() {
+		if err := emitArtifacts(out, items); err != nil {
+			return 2, err
+		}
+	}
+	return 0, nil
+}
+
+func emitArtifacts(out output, items []artifactItem) error {
+	rows := make([][]string, 0, len(items))
+	for _, item := range items {
+		rows = append(rows, []string{item.RunID, item.Path, strconv.FormatInt(item.Size, 10), item.Digest, orDash(item.Extracted)})
+	}
+	return out.emit(items, []string{"RUN", "PATH", "SIZE_BYTES", "DIGEST", "EXTRACTED"}, rows)
+}
+
+// resolveArtifactRun reads the record of the given run, or of the most
+// recent recorded run.
+func resolveArtifactRun(runDir string, runID string) (string, state.Record, error) {
+	if runID != "" {
+		record, err := state.ReadRecord(filepath.Join(runDir, runID+".json"))
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				return "", state.Record{}, fmt.Errorf("no record found for run: %s", runID)
+			}
+			return "", state.Record{}, err
+		}
+		return runID, record, nil
+	}
+
+	records, err := state.ListRecords(runDir)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return "", state.Record{}, fmt.Errorf("read run dir: %w", err)
+	}
+	if len(records) == 0 {
+		return "", state.Record{}, errors.New("no runs recorded")
+	}
+	latest := records[len(records)-1]
+	return latest.ID, latest.Record, nil
+}
+
+func selectArtifacts(artifacts []state.Artifact, paths []string) ([]state.Artifact, error) {
+	if len(paths) == 0 {
+		return artifacts, nil
+	}
+	selected := make([]state.Artifact, 0, len(paths))
+	for _, path := range paths {
+		found := false
+		for _, artifact := range artifacts {
+			if artifact.Path == filepath.ToSlash(path) {
+				selected = append(selected, artifact)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("unknown artifact: %s", path)
+		}
+	}
+	return selected, nil
+}
+
+// extractArtifact copies a stored artifact into dir and checks it still
+// matches the digest recorded for it.
+func extractArtifact(artifactsDir string, artifact state.Artifact, dir string) (string, error) {
+	algorithm, want, ok := strings.Cut(artifact.Digest, ":")
+	if !ok {
+		return "", fmt.Errorf("artifact %s: malformed digest %q", artifact.Path, artifact.Digest)
+	}
+	src := filepath.Join(artifactsDir, filepath.FromSlash(artifact.Path))
+	got, err := digest.File(src, algorithm)
+	if err != nil {
+		return "", fmt.Errorf("artifact %s: %w", artifact.Path, err)
+	}
+	if got != want {
+		return "", fmt.Errorf("artifact %s: digest mismatch", artifact.Path)
+	}
+
+	dest := filepath.Join(dir, filepath.FromSlash(artifact.Path))
+	if err := copyFile(src, dest); err != nil {
+		return "", fmt.Errorf("artifact %s: %w", artifact.Path, err)
+	}
+	return dest, nil
+}
+
+// captureArtifacts copies the files matching patterns, relative to
+// workingDir, into dir and digests each copy. A pattern that matches a
+// directory captures every file below it. Files are captured even when
+// another pattern fails, and the first error is returned alongside them.
+func captureArtifacts(patterns []string, workingDir string, dir string, algorithm string) ([]state.Artifact, error) {
+	base := workingDir
+	if base == "" {
+		base = "."
+	}
+
+	sources := make(map[string]string)
+	var firstErr error
+	fail := func(err error) {
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+	for _, pattern := range patterns {
+		glob := pattern
+		if !filepath.IsAbs(glob) {
+			glob = filepath.Join(base, glob)
+		}
+		matches, err := filepath.Glob(glob)
+		if err != nil {
+			fail(fmt.Errorf("artifact pattern %q: %w", pattern, err))
+			continue
+		}
+		if len(matches) == 0 {
+			fail(fmt.Errorf("artifact pattern %q matched no files", pattern))
+			continue
+		}
+		for _, match := range matches {
+			err := filepath.WalkDir(match, func(path string, entry fs.DirEntry, err error) error {
+				if err != nil {
+					return err
+				}
+				info, err := os.Stat(path)
+				if err != nil || !info.Mode().IsRegular() {
+					return err
+				}
+				rel := artifactPath(base, match, path)
+				if _, ok := sources[rel]; !ok {
+					sources[rel] = path
+				}
+				return nil
+			})
+			if err != nil {
+				fail(fmt.Errorf("artifact pattern %q: %w", pattern, err))
+			}
+		}
+	}
+
+	paths := make([]string, 0, len(sources))
+	for rel := range sources {
+		paths = append(paths, rel)
+	}
+	sort.Strings(paths)
+
+	artifacts := make([]state.Artifact, 0, len(paths))
+	for _, rel := range paths {
+		dest := filepath.Join(dir, filepath.FromSlash(rel))
+		if err := copyFile(sources[rel], dest); err != nil {
+			fail(fmt.Errorf("artifact %s: %w", rel, err))
+			continue
+		}
+		info, err := os.Stat(dest)
+		if err != nil {
+			fail(fmt.Errorf("artifact %s: %w", rel, err))
+			continue
+		}
+		sum, err := digest.File(dest, algorithm)
+		if err != nil {
+			fail(fmt.Errorf("artifact %s: %w", rel, err))
+			continue
+		}
+		artifacts = append(artifacts, state.Artifact{
+			Path:   rel,
+			Size:   info.Size(),
+			Digest: strings.ToLower(algorithm) + ":" + sum,
+		})
+	}
+	return artifacts, firstErr
+}
+
+// artifactPath names a captured file by its path below the working
+// directory. Files outside it keep their path below the matched entry.
+func artifactPath(base string, match string, path string) string {
+	if rel, err := filepath.Rel(base, path); err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return filepath.ToSlash(rel)
+	}
+	rel, err := filepath.Rel(filepath.Dir(match), path)
+	if err != nil {
+		return filepath.Base(path)
+	}
+	return filepath.ToSlash(rel)
+}
+
+func copyFile(src string, dest string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	info, err := in.Stat()
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dest), 0o755); err != nil {
+		return err
+	}
+	out, err := os.OpenFile(dest, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
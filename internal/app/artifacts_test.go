@@ -0,0 +1,64 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"orchastration/internal/digest"
+	"orchastration/internal/state"
+)
+
+func TestCaptureArtifacts(t *testing.T) {
+	work := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(work, "dist", "docs"), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	files := map[string]string{
+		"report.xml":          "<ok/>",
+		"dist/app":            "binary",
+		"dist/docs/index.txt": "docs",
+		"notes.md":            "not captured",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(work, filepath.FromSlash(name)), []byte(content), 0o644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+
+	dir := filepath.Join(t.TempDir(), "run.artifacts")
+	artifacts, err := captureArtifacts([]string{"*.xml", "dist", "missing/*"}, work, dir, "sha256")
+	if err == nil {
+		t.Fatalf("expected error for pattern with no matches")
+	}
+	if len(artifacts) != 3 {
+		t.Fatalf("expected 3 artifacts, got %+v", artifacts)
+	}
+	wantPaths := []string{"dist/app", "dist/docs/index.txt", "report.xml"}
+	for i, artifact := range artifacts {
+		if artifact.Path != wantPaths[i] {
+			t.Fatalf("artifact %d: expected %s, got %s", i, wantPaths[i], artifact.Path)
+		}
+		sum, err := digest.File(filepath.Join(work, filepath.FromSlash(artifact.Path)), "sha256")
+		if err != nil {
+			t.Fatalf("hash: %v", err)
+		}
+		if artifact.Digest != "sha256:"+sum || artifact.Size != int64(len(files[artifact.Path])) {
+			t.Fatalf("unexpected artifact %+v", artifact)
+		}
+	}
+
+	out := t.TempDir()
+	dest, err := extractArtifact(dir, artifacts[1], out)
+	if err != nil {
+		t.Fatalf("extract: %v", err)
+	}
+	if data, err := os.ReadFile(dest); err != nil || string(data) != "docs" {
+		t.Fatalf("unexpected extracted file %s: %q %v", dest, data, err)
+	}
+
+	tampered := state.Artifact{Path: "report.xml", Size: 5, Digest: "sha256:00"}
+	if _, err := extractArtifact(dir, tampered, out); err == nil {
+		t.Fatalf("expected digest mismatch")
+	}
+}
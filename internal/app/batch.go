@@ -0,0 +1,255 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"text/tabwriter"
+
+	"orchastration/internal/config"
+	"orchastration/internal/logging"
+	"orchastration/internal/runner"
+)
+
+const (
+	// statusSkipped marks jobs that did not run: a concurrency skip, a
+	// failed upstream, or an interrupted batch.
+	statusSkipped = "skipped"
+	// statusError marks jobs that failed before their command started.
+	statusError = "error"
+)
+
+// jobResult summarizes how one job invocation ended.
+type jobResult struct {
+	Status     string
+	Outcome    string
+	ExitCode   int
+	DurationMs int64
+	Runs       []runItem
+}
+
+// runItem is one recorded run, of a job or of one matrix cell, in the
+// structured output of run.
+type runItem struct {
+	RunID      string            `json:"run_id"`
+	Cell       map[string]string `json:"cell,omitempty"`
+	Status     string            `json:"status"`
+	Outcome    string            `json:"outcome"`
+	ExitCode   int               `json:"exit_code"`
+	DurationMs int64             `json:"duration_ms"`
+	CachedFrom string            `json:"cached_from,omitempty"`
+}
+
+// batchItem is one job in the structured output of run.
+type batchItem struct {
+	Job        string    `json:"job"`
+	Status     string    `json:"status"`
+	Outcome    string    `json:"outcome,omitempty"`
+	ExitCode   int       `json:"exit_code"`
+	DurationMs int64     `json:"duration_ms"`
+	Error      string    `json:"error,omitempty"`
+	Runs       []runItem `json:"runs,omitempty"`
+}
+
+// batchEntry is the outcome of one job in a batch run.
+type batchEntry struct {
+	Name   string
+	Result jobResult
+	Err    error
+}
+
+// selectJobs resolves the jobs named on the command line, or all jobs or
+// those with a tag, in a stable order.
+func selectJobs(cfg config.Config, names []string, all bool, tag string) ([]string, error) {
+	modes := 0
+	for _, set := range []bool{len(names) > 0, all, tag != ""} {
+		if set {
+			modes++
+		}
+	}
+	switch {
+	case modes == 0:
+		return nil, errors.New("run requires a job name, --all, or --tag")
+	case modes > 1:
+		return nil, errors.New("job names, --all, and --tag cannot be combined")
+	}
+
+	if len(names) > 0 {
+		selected := make([]string, 0, len(names))
+		for _, name := range names {
+			if _, ok := cfg.Jobs[name]; !ok {
+				return nil, fmt.Errorf("unknown job: %s", name)
+			}
+			if !containsString(selected, name) {
+				selected = append(selected, name)
+			}
+		}
+		return selected, nil
+	}
+
+	selected := make([]string, 0, len(cfg.Jobs))
+	for name, job := range cfg.Jobs {
+		if all || containsString(job.Tags, tag) {
+			selected = append(selected, name)
+		}
+	}
+	if len(selected) == 0 {
+		if all {
+			return nil, errors.New("no jobs configured")
+		}
+		return nil, fmt.Errorf("no jobs tagged %s", tag)
+	}
+	sort.Strings(selected)
+	return selected, nil
+}
+
+// dependencyClosure returns the targets and everything they depend on, in
+// an order where every job follows its dependencies.
+func dependencyClosure(jobs map[string]config.JobConfig, targets []string) ([]string, error) {
+	order := make([]string, 0, len(targets))
+	for _, target := range targets {
+		deps, err := config.JobDependencyOrder(jobs, target)
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range deps {
+			if !containsString(order, name) {
+				order = append(order, name)
+			}
+		}
+	}
+	return order, nil
+}
+
+// checkBatchParams rejects --param keys that no job in the batch declares.
+func checkBatchParams(cfg config.Config, names []string, params map[string]string) error {
+	for key := range params {
+		declared := false
+		for _, name := range names {
+			for _, param := range cfg.Jobs[name].Params {
+				if param.Name == key {
+					declared = true
+				}
+			}
+		}
+		if !declared {
+			return fmt.Errorf("no selected job has param: %s", key)
+		}
+	}
+	return nil
+}
+
+// runBatch runs the jobs with at most workers at once. A job waits for
+// those of its depends_on that are in the batch and is skipped if one of
+// them failed. Once interrupted, jobs not yet started are skipped.
+func runBatch(order []string, cfg config.Config, opts jobOptions, workers int, logger *logging.Logger, stateDir string, version string) []batchEntry {
+	index := make(map[string]int, len(order))
+	done := make([]chan struct{}, len(order))
+	for i, name := range order {
+		index[name] = i
+		done[i] = make(chan struct{})
+	}
+	entries := make([]batchEntry, len(order))
+
+	hub, stop := newSignalHub(opts.signals)
+	defer stop()
+
+	slots := make(chan struct{}, workers)
+	var wg sync.WaitGroup
+	for i, name := range order {
+		wg.Add(1)
+		go func(i int, name string) {
+			defer wg.Done()
+			defer close(done[i])
+			entry := &entries[i]
+			entry.Name = name
+			job := cfg.Jobs[name]
+
+			for _, dep := range job.DependsOn {
+				j, ok := index[dep]
+				if !ok {
+					continue
+				}
+				<-done[j]
+				if entries[j].Err != nil {
+					entry.Result = jobResult{Status: statusSkipped}
+					entry.Err = fmt.Errorf("upstream %s failed", dep)
+					logger.Warn("job skipped", "job", name, "upstream", dep)
+					opts.printf("job=%s skipped upstream=%s\n", name, dep)
+					return
+				}
+			}
+
+			slots <- struct{}{}
+			defer func() { <-slots }()
+			if hub.cancelled() {
+				entry.Result = jobResult{Status: statusSkipped}
+				entry.Err = runner.ErrCancelled
+				return
+			}
+			signals, unsubscribe := hub.subscribe()
+			defer unsubscribe()
+
+			jobOpts := opts
+			jobOpts.signals = signals
+			jobOpts.params = declaredParams(job.Params, opts.params)
+			entry.Result, entry.Err = executeJob(name, job, jobOpts, logger, stateDir, version)
+			autoPrune(cfg, logger, stateDir, name)
+		}(i, name)
+	}
+	wg.Wait()
+	return entries
+}
+
+// batchHeader and batchRow lay out the summary table of a run.
+var batchHeader = []string{"JOB", "STATUS", "OUTCOME", "EXIT", "DURATION_MS"}
+
+func batchRow(entry batchEntry) []string {
+	result, exit, duration := "-", "-", "-"
+	if entry.Result.Status != statusSkipped && entry.Result.Status != statusError {
+		result = entry.Result.Outcome
+		exit = strconv.Itoa(entry.Result.ExitCode)
+		duration = strconv.FormatInt(entry.Result.DurationMs, 10)
+	}
+	return []string{entry.Name, entry.Result.Status, result, exit, duration}
+}
+
+func printBatchSummary(w io.Writer, entries []batchEntry) {
+	table := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, strings.Join(batchHeader, "\t"))
+	failed := 0
+	for _, entry := range entries {
+		if entry.Err != nil {
+			failed++
+		}
+		fmt.Fprintln(table, strings.Join(batchRow(entry), "\t"))
+	}
+	table.Flush()
+	fmt.Fprintf(w, "jobs=%d ok=%d failed=%d\n", len(entries), len(entries)-failed, failed)
+}
+
+// emitBatch writes the result of each job in entries as structured output.
+func emitBatch(out output, entries []batchEntry) error {
+	items := make([]batchItem, 0, len(entries))
+	rows := make([][]string, 0, len(entries))
+	for _, entry := range entries {
+		item := batchItem{
+			Job:        entry.Name,
+			Status:     entry.Result.Status,
+			Outcome:    entry.Result.Outcome,
+			ExitCode:   entry.Result.ExitCode,
+			DurationMs: entry.Result.DurationMs,
+			Runs:       entry.Result.Runs,
+		}
+		if entry.Err != nil {
+			item.Error = entry.Err.Error()
+		}
+		items = append(items, item)
+		rows = append(rows, batchRow(entry))
+	}
+	return out.emit(items, batchHeader, rows)
+}
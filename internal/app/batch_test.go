@@ -0,0 +1,119 @@
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"orchastration/internal/config"
+	"orchastration/internal/logging"
+	"orchastration/internal/secrets"
+)
+
+func TestSelectJobs(t *testing.T) {
+	cfg := config.Config{Jobs: map[string]config.JobConfig{
+		"build": {Command: []string{"true"}, Tags: []string{"ci"}},
+		"test":  {Command: []string{"true"}, Tags: []string{"ci", "slow"}},
+		"clean": {Command: []string{"true"}},
+	}}
+
+	got, err := selectJobs(cfg, nil, false, "ci")
+	if err != nil || strings.Join(got, ",") != "build,test" {
+		t.Fatalf("tag selection: %v %v", got, err)
+	}
+	got, err = selectJobs(cfg, nil, true, "")
+	if err != nil || strings.Join(got, ",") != "build,clean,test" {
+		t.Fatalf("all selection: %v %v", got, err)
+	}
+	got, err = selectJobs(cfg, []string{"test", "build", "test"}, false, "")
+	if err != nil || strings.Join(got, ",") != "test,build" {
+		t.Fatalf("name selection: %v %v", got, err)
+	}
+	if _, err := selectJobs(cfg, []string{"build"}, true, ""); err == nil {
+		t.Fatalf("expected error combining names and --all")
+	}
+	if _, err := selectJobs(cfg, nil, false, "missing"); err == nil {
+		t.Fatalf("expected error for unused tag")
+	}
+	if _, err := selectJobs(cfg, []string{"nope"}, false, ""); err == nil {
+		t.Fatalf("expected error for unknown job")
+	}
+}
+
+func TestDependencyClosure(t *testing.T) {
+	jobs := map[string]config.JobConfig{
+		"fetch":  {Command: []string{"true"}},
+		"build":  {Command: []string{"true"}, DependsOn: []string{"fetch"}},
+		"lint":   {Command: []string{"true"}, DependsOn: []string{"fetch"}},
+		"deploy": {Command: []string{"true"}, DependsOn: []string{"build"}},
+	}
+	order, err := dependencyClosure(jobs, []string{"deploy", "lint"})
+	if err != nil {
+		t.Fatalf("closure: %v", err)
+	}
+	if strings.Join(order, ",") != "fetch,build,deploy,lint" {
+		t.Fatalf("unexpected order: %v", order)
+	}
+}
+
+func TestRunBatchSkipsAfterFailedUpstream(t *testing.T) {
+	cfg := config.Config{Jobs: map[string]config.JobConfig{
+		"fail":  {Command: []string{"false"}},
+		"after": {Command: []string{"true"}, DependsOn: []string{"fail"}},
+		"other": {Command: []string{"true"}},
+	}}
+	logger := &logging.Logger{Logger: slog.New(slog.NewTextHandler(io.Discard, nil))}
+	opts := jobOptions{secrets: secrets.NewResolver(cfg.Secrets)}
+
+	entries := runBatch([]string{"fail", "after", "other"}, cfg, opts, 2, logger, t.TempDir(), "test")
+	if entries[0].Err == nil || entries[0].Result.ExitCode == 0 {
+		t.Fatalf("expected fail to fail: %+v", entries[0])
+	}
+	if entries[1].Err == nil || entries[1].Result.Status != statusSkipped {
+		t.Fatalf("expected after to be skipped: %+v", entries[1])
+	}
+	if entries[2].Err != nil {
+		t.Fatalf("expected other to succeed: %+v", entries[2])
+	}
+
+	var out bytes.Buffer
+	printBatchSummary(&out, entries)
+	if !strings.Contains(out.String(), "jobs=3 ok=1 failed=2") {
+		t.Fatalf("unexpected summary:\n%s", out.String())
+	}
+}
+
+func TestEmitBatchStructured(t *testing.T) {
+	cfg := config.Config{Jobs: map[string]config.JobConfig{
+		"fail":  {Command: []string{"false"}},
+		"other": {Command: []string{"true"}},
+	}}
+	logger := &logging.Logger{Logger: slog.New(slog.NewTextHandler(io.Discard, nil))}
+	opts := jobOptions{secrets: secrets.NewResolver(cfg.Secrets), structured: true}
+	entries := runBatch([]string{"fail", "other"}, cfg, opts, 1, logger, t.TempDir(), "test")
+
+	var buf bytes.Buffer
+	if err := emitBatch(output{format: outputJSON, command: "run", w: &buf}, entries); err != nil {
+		t.Fatalf("emitBatch: %v", err)
+	}
+	var doc struct {
+		Command string      `json:"command"`
+		Items   []batchItem `json:"items"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("parse: %v\n%s", err, buf.String())
+	}
+	if doc.Command != "run" || len(doc.Items) != 2 {
+		t.Fatalf("unexpected document:\n%s", buf.String())
+	}
+	failed, ok := doc.Items[0], doc.Items[1]
+	if failed.Job != "fail" || failed.ExitCode != 1 || failed.Error == "" || len(failed.Runs) != 1 || failed.Runs[0].RunID == "" {
+		t.Fatalf("unexpected failed item: %+v", failed)
+	}
+	if ok.Job != "other" || ok.Status != "success" || ok.Error != "" || len(ok.Runs) != 1 {
+		t.Fatalf("unexpected item: %+v", ok)
+	}
+}
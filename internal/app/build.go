@@ -2,7 +2,9 @@ package app
 
 import (
 	"errors"
+	"flag"
 	"fmt"
+	"io"
 	"os"
 
 	"orchastration/internal/config"
@@ -25,10 +27,17 @@ func runBuild(args []string, cfg config.Config, logger *logging.Logger, stateDir
 }
 
 func buildRun(args []string, cfg config.Config, logger *logging.Logger, stateDir string) (int, error) {
-	if len(args) == 0 {
+	fs := flag.NewFlagSet("build run", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	force := fs.Bool("force", false, "run even when the task's inputs are unchanged")
+	positional, err := parseInterspersed(fs, args)
+	if err != nil {
+		return 2, err
+	}
+	if len(positional) == 0 {
 		return 2, errors.New("build run requires a task name")
 	}
 
-	name := args[0]
-	return taskflow.BuildRun(name, cfg, logger, stateDir, os.Stdout)
+	name := positional[0]
+	return taskflow.BuildRun(name, cfg, logger, stateDir, os.Stdout, taskflow.BuildOptions{Force: *force})
 }
@@ -0,0 +1,69 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+
+	"orchastration/internal/config"
+	"orchastration/internal/digest"
+	"orchastration/internal/runner"
+	"orchastration/internal/state"
+)
+
+// jobInputsDigest digests the job's inputs together with its rendered
+// command and configured environment.
+func jobInputsDigest(job config.JobConfig, algorithm string) (string, error) {
+	return digest.Inputs(digest.Spec{
+		Dir:      job.WorkingDir,
+		Patterns: job.Inputs,
+		Command:  job.Command,
+		Env:      job.Env,
+		EnvFiles: job.EnvFiles,
+	}, algorithm)
+}
+
+// findCachedRun returns the last successful run of the cell when its inputs
+// digest matches and the job's artifacts still exist, so the run can be
+// skipped.
+func findCachedRun(runDir string, job config.JobConfig, cell map[string]string, inputsDigest string) (state.RecordEntry, bool) {
+	entries, err := state.ListRecords(runDir)
+	if err != nil {
+		return state.RecordEntry{}, false
+	}
+	key := cellKey(cell)
+	for i := len(entries) - 1; i >= 0; i-- {
+		entry := entries[i]
+		if cellKey(entry.Record.Matrix) != key {
+			continue
+		}
+		if entry.Record.Status != runner.StatusSuccess && entry.Record.Status != state.StatusCached {
+			continue
+		}
+		if entry.Record.InputsDigest != inputsDigest || !outputsExist(job, entry.Record) {
+			return state.RecordEntry{}, false
+		}
+		return entry, true
+	}
+	return state.RecordEntry{}, false
+}
+
+// outputsExist reports whether the artifacts of record are still in place.
+// A scratch workspace is usually removed once its run ends, so for those
+// jobs the files the run captured count instead of working_dir.
+func outputsExist(job config.JobConfig, record state.Record) bool {
+	if job.Workspace != workspaceScratch {
+		return digest.Exist(job.Artifacts, job.WorkingDir)
+	}
+	if len(job.Artifacts) == 0 {
+		return true
+	}
+	if record.ArtifactsDir == "" || len(record.Artifacts) == 0 {
+		return false
+	}
+	for _, artifact := range record.Artifacts {
+		if _, err := os.Stat(filepath.Join(record.ArtifactsDir, filepath.FromSlash(artifact.Path))); err != nil {
+			return false
+		}
+	}
+	return true
+}
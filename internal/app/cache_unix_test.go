@@ -0,0 +1,124 @@
+//go:build !windows
+
+package app
+
+import (
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"orchastration/internal/config"
+	"orchastration/internal/logging"
+	"orchastration/internal/secrets"
+	"orchastration/internal/state"
+)
+
+func TestExecuteJobSkipsUnchangedInputs(t *testing.T) {
+	work := t.TempDir()
+	stateDir := t.TempDir()
+	input := filepath.Join(work, "in.txt")
+	if err := os.WriteFile(input, []byte("v1"), 0o644); err != nil {
+		t.Fatalf("write input: %v", err)
+	}
+	job := config.JobConfig{
+		Command:    []string{"sh", "-c", "echo ran >> runs.log; cp in.txt out.txt"},
+		WorkingDir: work,
+		Inputs:     []string{"in.txt"},
+		Artifacts:  []string{"out.txt"},
+	}
+	logger := &logging.Logger{Logger: slog.New(slog.NewTextHandler(io.Discard, nil))}
+	opts := jobOptions{secrets: secrets.NewResolver(config.SecretsConfig{}), hashAlgorithm: "sha256"}
+
+	run := func(opts jobOptions, want string) {
+		t.Helper()
+		result, err := executeJob("build", job, opts, logger, stateDir, "test")
+		if err != nil {
+			t.Fatalf("executeJob: %v", err)
+		}
+		if result.Status != want {
+			t.Fatalf("expected status %s, got %s", want, result.Status)
+		}
+	}
+
+	run(opts, "success")
+	run(opts, state.StatusCached)
+	forced := opts
+	forced.force = true
+	run(forced, "success")
+
+	if err := os.Remove(filepath.Join(work, "out.txt")); err != nil {
+		t.Fatalf("remove output: %v", err)
+	}
+	run(opts, "success")
+	if err := os.WriteFile(input, []byte("v2"), 0o644); err != nil {
+		t.Fatalf("write input: %v", err)
+	}
+	run(opts, "success")
+	run(opts, state.StatusCached)
+
+	last, err := state.ReadRecord(filepath.Join(stateDir, "runs", "build", "last.json"))
+	if err != nil {
+		t.Fatalf("read last record: %v", err)
+	}
+	if last.CachedFrom == "" || last.InputsDigest == "" {
+		t.Fatalf("expected a cached record, got %+v", last)
+	}
+	ran, err := os.ReadFile(filepath.Join(work, "runs.log"))
+	if err != nil {
+		t.Fatalf("read runs.log: %v", err)
+	}
+	if got := len(ran) / len("ran\n"); got != 4 {
+		t.Fatalf("expected 4 executions, got %d", got)
+	}
+}
+
+func TestExecuteJobSkipsUnchangedScratchJob(t *testing.T) {
+	work := t.TempDir()
+	stateDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(work, "in.txt"), []byte("v1"), 0o644); err != nil {
+		t.Fatalf("write input: %v", err)
+	}
+	job := config.JobConfig{
+		Command:       []string{"cp", "in.txt", "out.txt"},
+		WorkingDir:    work,
+		Workspace:     workspaceScratch,
+		WorkspaceSeed: []string{"in.txt"},
+		Inputs:        []string{"in.txt"},
+		Artifacts:     []string{"out.txt"},
+	}
+	logger := &logging.Logger{Logger: slog.New(slog.NewTextHandler(io.Discard, nil))}
+	opts := jobOptions{secrets: secrets.NewResolver(config.SecretsConfig{}), hashAlgorithm: "sha256"}
+
+	run := func(want string) state.Record {
+		t.Helper()
+		result, err := executeJob("build", job, opts, logger, stateDir, "test")
+		if err != nil {
+			t.Fatalf("executeJob: %v", err)
+		}
+		if result.Status != want {
+			t.Fatalf("expected status %s, got %s", want, result.Status)
+		}
+		last, err := state.ReadRecord(filepath.Join(stateDir, "runs", "build", "last.json"))
+		if err != nil {
+			t.Fatalf("read last record: %v", err)
+		}
+		return last
+	}
+
+	first := run("success")
+	if first.Workspace != "" {
+		t.Fatalf("expected the workspace to be removed, got %s", first.Workspace)
+	}
+	cached := run(state.StatusCached)
+	if cached.ArtifactsDir != first.ArtifactsDir || len(cached.Artifacts) != 1 {
+		t.Fatalf("expected the cached record to point at the captured artifacts, got %+v", cached)
+	}
+	run(state.StatusCached)
+
+	if err := os.RemoveAll(first.ArtifactsDir); err != nil {
+		t.Fatalf("remove artifacts: %v", err)
+	}
+	run("success")
+}
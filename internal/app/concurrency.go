@@ -0,0 +1,116 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"syscall"
+	"time"
+
+	"orchastration/internal/lock"
+	"orchastration/internal/logging"
+	"orchastration/internal/runner"
+)
+
+// lockPollInterval is how often queued runs retry the lock and running
+// jobs check for replace requests.
+var lockPollInterval = 500 * time.Millisecond
+
+func jobLockPath(stateDir string, jobName string) string {
+	return filepath.Join(stateDir, "locks", jobName+".lock")
+}
+
+// replacePath marks a request for the run holding the lock to cancel.
+func replacePath(lockPath string) string {
+	return lockPath + ".replace"
+}
+
+// acquireJobLock applies the job's concurrency policy. It returns a nil
+// lock under "allow", and skipped when "skip" finds the job already running.
+func acquireJobLock(jobName string, policy string, logger *logging.Logger, stateDir string, signals <-chan os.Signal) (*lock.Lock, bool, error) {
+	if policy == "" || policy == "allow" {
+		return nil, false, nil
+	}
+
+	path := jobLockPath(stateDir, jobName)
+	requested := false
+	for {
+		held, err := lock.TryAcquire(path, lock.Current(time.Now()))
+		if err == nil {
+			// A request left by a crashed holder must not cancel this run.
+			_ = os.Remove(replacePath(path))
+			return held, false, nil
+		}
+		var locked *lock.LockedError
+		if !errors.As(err, &locked) {
+			return nil, false, fmt.Errorf("job %s lock: %w", jobName, err)
+		}
+
+		switch policy {
+		case "skip":
+			logger.Warn("job skipped", "job", jobName, "locked_by", locked.Holder.PID, "host", locked.Holder.Host)
+			return nil, true, nil
+		case "replace":
+			if !requested {
+				logger.Warn("job replacing running instance", "job", jobName, "pid", locked.Holder.PID, "host", locked.Holder.Host)
+				if err := os.WriteFile(replacePath(path), []byte(strconv.Itoa(os.Getpid())), 0o644); err != nil {
+					return nil, false, fmt.Errorf("job %s replace: %w", jobName, err)
+				}
+				requested = true
+			}
+		case "queue":
+			if !requested {
+				logger.Info("job queued", "job", jobName, "locked_by", locked.Holder.PID, "host", locked.Holder.Host)
+				requested = true
+			}
+		}
+		if err := runner.Sleep(lockPollInterval, signals); err != nil {
+			return nil, false, fmt.Errorf("job %s: %w", jobName, err)
+		}
+	}
+}
+
+// watchReplace passes signals through and adds a SIGTERM once another run
+// asks to replace this one. stop ends the watch.
+func watchReplace(lockPath string, signals <-chan os.Signal) (<-chan os.Signal, func()) {
+	out := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(lockPollInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case sig := <-signals:
+				select {
+				case out <- sig:
+				default:
+				}
+			case <-ticker.C:
+				if err := os.Remove(replacePath(lockPath)); err == nil {
+					select {
+					case out <- syscall.SIGTERM:
+					default:
+					}
+				}
+			}
+		}
+	}()
+	return out, func() { close(done) }
+}
+
+// lockSummary describes who holds the job's lock, for status output.
+func lockSummary(stateDir string, jobName string) string {
+	holder, err := lock.Read(jobLockPath(stateDir, jobName))
+	if err != nil {
+		return ""
+	}
+	summary := fmt.Sprintf(" locked_by=%d host=%s since=%s", holder.PID, holder.Host, holder.StartTime)
+	if holder.Stale() {
+		summary += " stale=true"
+	}
+	return summary
+}
@@ -0,0 +1,265 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"sync"
+	"syscall"
+	"time"
+
+	"orchastration/internal/config"
+	"orchastration/internal/logging"
+	"orchastration/internal/notify"
+	"orchastration/internal/schedule"
+	"orchastration/internal/secrets"
+	"orchastration/internal/state"
+)
+
+type scheduledJob struct {
+	name     string
+	job      config.JobConfig
+	schedule *schedule.Schedule
+}
+
+type daemon struct {
+	jobs      []scheduledJob
+	logger    *logging.Logger
+	statePath string
+	now       func() time.Time
+	runJob    func(name string, job config.JobConfig) (int, error)
+
+	mu      sync.Mutex
+	wg      sync.WaitGroup
+	state   state.ScheduleState
+	next    map[string]time.Time
+	running map[string]bool
+}
+
+func runDaemon(args []string, cfg config.Config, logger *logging.Logger, stateDir string, version string) (int, error) {
+	fs := flag.NewFlagSet("daemon", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	if err := fs.Parse(args); err != nil {
+		return 2, err
+	}
+
+	d, err := newDaemon(cfg, logger, stateDir, func(name string, job config.JobConfig) (int, error) {
+		// A fresh resolver per run picks up rotated secrets.
+		resolver := secrets.NewResolver(cfg.Secrets)
+		opts := jobOptions{
+			secrets:       resolver,
+			hashAlgorithm: cfg.Hash.Algorithm,
+			notifier:      notify.New(cfg.Notifications, resolver, logger),
+		}
+		_, err := executeJob(name, job, opts, logger, stateDir, version)
+		autoPrune(cfg, logger, stateDir, name)
+		if err != nil {
+			return 2, err
+		}
+		return 0, nil
+	})
+	if err != nil {
+		return 2, err
+	}
+	if len(d.jobs) == 0 {
+		return 2, errors.New("no scheduled jobs configured")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	reconcileRuns(stateDir, logger)
+	logger.Info("daemon started", "jobs", len(d.jobs))
+	if err := d.run(ctx); err != nil {
+		logger.Error("daemon failed", "error", err)
+		return 2, err
+	}
+	logger.Info("daemon stopped")
+	return 0, nil
+}
+
+func newDaemon(cfg config.Config, logger *logging.Logger, stateDir string, run func(string, config.JobConfig) (int, error)) (*daemon, error) {
+	names := make([]string, 0, len(cfg.Jobs))
+	for name, job := range cfg.Jobs {
+		if job.Schedule != "" {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	jobs := make([]scheduledJob, 0, len(names))
+	for _, name := range names {
+		job := cfg.Jobs[name]
+		sched, err := schedule.Parse(job.Schedule, job.Timezone)
+		if err != nil {
+			return nil, fmt.Errorf("job %s schedule: %w", name, err)
+		}
+		jobs = append(jobs, scheduledJob{name: name, job: job, schedule: sched})
+	}
+
+	return &daemon{
+		jobs:      jobs,
+		logger:    logger,
+		statePath: filepath.Join(stateDir, "daemon", "schedule.json"),
+		now:       time.Now,
+		runJob:    run,
+		next:      make(map[string]time.Time, len(jobs)),
+		running:   make(map[string]bool, len(jobs)),
+	}, nil
+}
+
+func (d *daemon) run(ctx context.Context) error {
+	if err := d.prime(d.now()); err != nil {
+		return err
+	}
+
+	for {
+		wait := time.Until(d.earliest())
+		if wait < 0 {
+			wait = 0
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			d.wg.Wait()
+			return d.save()
+		case <-timer.C:
+			if err := d.fireDue(d.now()); err != nil {
+				d.logger.Error("failed to write schedule state", "error", err)
+			}
+		}
+	}
+}
+
+// prime loads persisted fire times, catches up missed runs for jobs that
+// opt in, and computes the next fire time for every scheduled job.
+func (d *daemon) prime(now time.Time) error {
+	loaded, err := state.ReadSchedule(d.statePath)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	d.mu.Lock()
+	d.state = loaded
+	if d.state.Jobs == nil {
+		d.state.Jobs = make(map[string]state.ScheduleEntry, len(d.jobs))
+	}
+	d.mu.Unlock()
+
+	for _, sj := range d.jobs {
+		d.mu.Lock()
+		entry := d.state.Jobs[sj.name]
+		d.mu.Unlock()
+
+		missed := false
+		if entry.Schedule == sj.job.Schedule && entry.NextFire != "" {
+			if nextFire, err := time.Parse(time.RFC3339, entry.NextFire); err == nil && !nextFire.After(now) {
+				missed = true
+			}
+		}
+		if missed && sj.job.CatchUp {
+			d.logger.Info("catching up missed run", "job", sj.name, "missed_fire", entry.NextFire)
+			d.fire(sj, now)
+		} else if missed {
+			d.logger.Warn("missed scheduled run", "job", sj.name, "missed_fire", entry.NextFire)
+		}
+		d.schedule(sj, now)
+	}
+	return d.save()
+}
+
+func (d *daemon) fireDue(now time.Time) error {
+	for _, sj := range d.jobs {
+		d.mu.Lock()
+		due := !d.next[sj.name].After(now)
+		d.mu.Unlock()
+		if !due {
+			continue
+		}
+		d.fire(sj, now)
+		d.schedule(sj, now)
+	}
+	return d.save()
+}
+
+func (d *daemon) fire(sj scheduledJob, now time.Time) {
+	d.mu.Lock()
+	if d.running[sj.name] {
+		d.mu.Unlock()
+		d.logger.Warn("job still running, skipping scheduled run", "job", sj.name)
+		return
+	}
+	d.running[sj.name] = true
+	entry := d.state.Jobs[sj.name]
+	entry.LastFire = now.UTC().Format(time.RFC3339)
+	d.state.Jobs[sj.name] = entry
+	d.mu.Unlock()
+
+	d.wg.Add(1)
+	go func() {
+		defer d.wg.Done()
+		_, err := d.runJob(sj.name, sj.job)
+
+		d.mu.Lock()
+		d.running[sj.name] = false
+		entry := d.state.Jobs[sj.name]
+		entry.LastError = ""
+		if err != nil {
+			entry.LastError = err.Error()
+		}
+		d.state.Jobs[sj.name] = entry
+		d.mu.Unlock()
+
+		if err := d.save(); err != nil {
+			d.logger.Error("failed to write schedule state", "error", err)
+		}
+	}()
+}
+
+func (d *daemon) schedule(sj scheduledJob, now time.Time) {
+	next := sj.schedule.Next(now)
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.next[sj.name] = next
+	entry := d.state.Jobs[sj.name]
+	entry.JobName = sj.name
+	entry.Schedule = sj.job.Schedule
+	entry.Timezone = sj.job.Timezone
+	entry.NextFire = ""
+	if !next.IsZero() {
+		entry.NextFire = next.UTC().Format(time.RFC3339)
+	}
+	d.state.Jobs[sj.name] = entry
+}
+
+func (d *daemon) earliest() time.Time {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	var earliest time.Time
+	for _, next := range d.next {
+		if next.IsZero() {
+			continue
+		}
+		if earliest.IsZero() || next.Before(earliest) {
+			earliest = next
+		}
+	}
+	if earliest.IsZero() {
+		return d.now().Add(time.Hour)
+	}
+	return earliest
+}
+
+func (d *daemon) save() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return state.WriteSchedule(d.statePath, d.state)
+}
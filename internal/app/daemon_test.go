@@ -0,0 +1,70 @@
+package app
+
+import (
+	"io"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"orchastration/internal/config"
+	"orchastration/internal/logging"
+	"orchastration/internal/state"
+)
+
+func TestDaemonCatchUpAndNextFire(t *testing.T) {
+	stateDir := t.TempDir()
+	cfg := config.Config{Jobs: map[string]config.JobConfig{
+		"nightly": {Command: []string{"true"}, Schedule: "0 2 * * *", Timezone: "UTC", CatchUp: true},
+		"hourly":  {Command: []string{"true"}, Schedule: "@hourly", Timezone: "UTC"},
+		"manual":  {Command: []string{"true"}},
+	}}
+
+	var mu sync.Mutex
+	ran := []string{}
+	logger := &logging.Logger{Logger: slog.New(slog.NewTextHandler(io.Discard, nil))}
+	d, err := newDaemon(cfg, logger, stateDir, func(name string, _ config.JobConfig) (int, error) {
+		mu.Lock()
+		ran = append(ran, name)
+		mu.Unlock()
+		return 0, nil
+	})
+	if err != nil {
+		t.Fatalf("newDaemon: %v", err)
+	}
+	if len(d.jobs) != 2 {
+		t.Fatalf("expected 2 scheduled jobs, got %d", len(d.jobs))
+	}
+
+	missed := state.ScheduleState{Jobs: map[string]state.ScheduleEntry{
+		"nightly": {JobName: "nightly", Schedule: "0 2 * * *", NextFire: "2026-03-09T02:00:00Z"},
+		"hourly":  {JobName: "hourly", Schedule: "@hourly", NextFire: "2026-03-09T03:00:00Z"},
+	}}
+	if err := state.WriteSchedule(d.statePath, missed); err != nil {
+		t.Fatalf("write schedule: %v", err)
+	}
+
+	now := time.Date(2026, 3, 10, 12, 30, 0, 0, time.UTC)
+	if err := d.prime(now); err != nil {
+		t.Fatalf("prime: %v", err)
+	}
+	d.wg.Wait()
+
+	if len(ran) != 1 || ran[0] != "nightly" {
+		t.Fatalf("expected only nightly to catch up, got %v", ran)
+	}
+
+	saved, err := state.ReadSchedule(d.statePath)
+	if err != nil {
+		t.Fatalf("read schedule: %v", err)
+	}
+	if saved.Jobs["nightly"].NextFire != "2026-03-11T02:00:00Z" {
+		t.Fatalf("unexpected nightly next fire: %s", saved.Jobs["nightly"].NextFire)
+	}
+	if saved.Jobs["nightly"].LastFire != "2026-03-10T12:30:00Z" {
+		t.Fatalf("unexpected nightly last fire: %s", saved.Jobs["nightly"].LastFire)
+	}
+	if saved.Jobs["hourly"].NextFire != "2026-03-10T13:00:00Z" {
+		t.Fatalf("unexpected hourly next fire: %s", saved.Jobs["hourly"].NextFire)
+	}
+}
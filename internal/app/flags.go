@@ -0,0 +1,20 @@
+package app
+
+import "flag"
+
+// parseInterspersed parses flags that may appear before or after positional
+// arguments, such as `history nightly --limit 5`, and returns the positionals.
+func parseInterspersed(fs *flag.FlagSet, args []string) ([]string, error) {
+	positional := make([]string, 0, len(args))
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
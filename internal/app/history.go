@@ -0,0 +1,268 @@
+package app
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+
+	"orchastration/internal/config"
+	"orchastration/internal/outcome"
+	"orchastration/internal/state"
+)
+
+type historyRun struct {
+	ID     string `json:"id"`
+	Status string `json:"status"`
+	state.Record
+}
+
+type historyFailure struct {
+	ID        string `json:"id"`
+	StartTime string `json:"start_time"`
+	ExitCode  int    `json:"exit_code"`
+}
+
+type historySummary struct {
+	Runs          int             `json:"runs"`
+	Successes     int             `json:"successes"`
+	Failures      int             `json:"failures"`
+	SuccessRate   float64         `json:"success_rate"`
+	P50DurationMs int64           `json:"p50_duration_ms"`
+	P95DurationMs int64           `json:"p95_duration_ms"`
+	LastFailure   *historyFailure `json:"last_failure,omitempty"`
+}
+
+// historyCell summarizes the runs of one matrix cell.
+type historyCell struct {
+	Cell    string         `json:"cell"`
+	Summary historySummary `json:"summary"`
+}
+
+type historyReport struct {
+	JobName string         `json:"job_name"`
+	Summary historySummary `json:"summary"`
+	Cells   []historyCell  `json:"cells,omitempty"`
+	Runs    []historyRun   `json:"runs"`
+}
+
+type historyFilter struct {
+	since  time.Time
+	until  time.Time
+	status string
+	limit  int
+}
+
+func runHistory(args []string, cfg config.Config, stateDir string, out output) (int, error) {
+	fs := flag.NewFlagSet("history", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	since := fs.String("since", "", "only runs starting at or after this time (RFC3339, YYYY-MM-DD, or a duration like 24h)")
+	until := fs.String("until", "", "only runs starting before this time (RFC3339, YYYY-MM-DD, or a duration like 24h)")
+	status := fs.String("status", "", "only runs with this status (success, failed, cancelled, timed_out, cached, running, abandoned)")
+	limit := fs.Int("limit", 0, "only the most recent N runs (0 means all)")
+	positional, err := parseInterspersed(fs, args)
+	if err != nil {
+		return 2, err
+	}
+	if len(positional) == 0 {
+		return 2, errors.New("history requires a job name")
+	}
+
+	jobName := positional[0]
+	if _, ok := cfg.Jobs[jobName]; !ok {
+		return 2, fmt.Errorf("unknown job: %s", jobName)
+	}
+
+	now := time.Now().UTC()
+	filter := historyFilter{status: *status, limit: *limit}
+	if filter.since, err = parseHistoryTime(*since, now); err != nil {
+		return 2, fmt.Errorf("invalid --since: %w", err)
+	}
+	if filter.until, err = parseHistoryTime(*until, now); err != nil {
+		return 2, fmt.Errorf("invalid --until: %w", err)
+	}
+
+	entries, err := state.ListRecords(filepath.Join(stateDir, "runs", jobName))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return 2, fmt.Errorf("read run history: %w", err)
+	}
+
+	report := buildHistory(jobName, entries, filter)
+	if out.structured() {
+		if err := emitHistory(out, report); err != nil {
+			return 2, err
+		}
+		return 0, nil
+	}
+	printHistory(os.Stdout, report)
+	return 0, nil
+}
+
+// emitHistory writes the report as the single item of structured output;
+// a table lists its runs.
+func emitHistory(out output, report historyReport) error {
+	rows := make([][]string, 0, len(report.Runs))
+	for _, run := range report.Runs {
+		rows = append(rows, []string{run.ID, run.Status, strconv.Itoa(run.ExitCode), strconv.FormatInt(run.DurationMs, 10), run.StartTime, orDash(cellKey(run.Matrix))})
+	}
+	return out.emit([]historyReport{report}, []string{"RUN", "STATUS", "EXIT", "DURATION_MS", "START", "CELL"}, rows)
+}
+
+func buildHistory(jobName string, entries []state.RecordEntry, filter historyFilter) historyReport {
+	runs := make([]historyRun, 0, len(entries))
+	for _, entry := range entries {
+		run := historyRun{ID: entry.ID, Status: recordStatus(entry.Record), Record: entry.Record}
+		if filter.status != "" && run.Status != filter.status {
+			continue
+		}
+		if filter.status == "" && run.Status == state.StatusRunning {
+			continue
+		}
+		started, err := time.Parse(time.RFC3339, entry.Record.StartTime)
+		if err == nil {
+			if !filter.since.IsZero() && started.Before(filter.since) {
+				continue
+			}
+			if !filter.until.IsZero() && !started.Before(filter.until) {
+				continue
+			}
+		}
+		runs = append(runs, run)
+	}
+	if filter.limit > 0 && len(runs) > filter.limit {
+		runs = runs[len(runs)-filter.limit:]
+	}
+
+	return historyReport{
+		JobName: jobName,
+		Summary: summarizeHistory(runs),
+		Cells:   summarizeCells(runs),
+		Runs:    runs,
+	}
+}
+
+// summarizeCells groups matrix runs by cell. Runs without a matrix are
+// left out.
+func summarizeCells(runs []historyRun) []historyCell {
+	grouped := make(map[string][]historyRun)
+	for _, run := range runs {
+		if len(run.Matrix) == 0 {
+			continue
+		}
+		key := cellKey(run.Matrix)
+		grouped[key] = append(grouped[key], run)
+	}
+	if len(grouped) == 0 {
+		return nil
+	}
+
+	keys := make([]string, 0, len(grouped))
+	for key := range grouped {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	cells := make([]historyCell, 0, len(keys))
+	for _, key := range keys {
+		cells = append(cells, historyCell{Cell: key, Summary: summarizeHistory(grouped[key])})
+	}
+	return cells
+}
+
+func summarizeHistory(runs []historyRun) historySummary {
+	summary := historySummary{Runs: len(runs)}
+	if len(runs) == 0 {
+		return summary
+	}
+
+	durations := make([]int64, 0, len(runs))
+	for _, run := range runs {
+		durations = append(durations, run.DurationMs)
+		if runSucceeded(run) {
+			summary.Successes++
+			continue
+		}
+		summary.Failures++
+		summary.LastFailure = &historyFailure{ID: run.ID, StartTime: run.StartTime, ExitCode: run.ExitCode}
+	}
+	sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+
+	summary.SuccessRate = float64(summary.Successes) / float64(summary.Runs)
+	summary.P50DurationMs = percentile(durations, 50)
+	summary.P95DurationMs = percentile(durations, 95)
+	return summary
+}
+
+// runSucceeded counts a run by its outcome, so a failure allowed by
+// allow_failure counts as the success run reports it as. Records written
+// before outcomes existed fall back to their status.
+func runSucceeded(run historyRun) bool {
+	if run.Outcome != "" {
+		return run.Outcome != outcome.Failure
+	}
+	return run.Status == "success" || run.Status == state.StatusCached
+}
+
+// percentile uses the nearest-rank method on sorted values.
+func percentile(sorted []int64, p float64) int64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+func recordStatus(record state.Record) string {
+	if record.Status != "" {
+		return record.Status
+	}
+	if record.ExitCode == 0 {
+		return "success"
+	}
+	return "failed"
+}
+
+func parseHistoryTime(value string, now time.Time) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if ts, err := time.Parse(time.RFC3339, value); err == nil {
+		return ts, nil
+	}
+	if ts, err := time.Parse("2006-01-02", value); err == nil {
+		return ts, nil
+	}
+	if d, err := time.ParseDuration(value); err == nil {
+		return now.Add(-d), nil
+	}
+	return time.Time{}, fmt.Errorf("unrecognized time: %s", value)
+}
+
+func printHistory(w io.Writer, report historyReport) {
+	summary := report.Summary
+	if summary.Runs == 0 {
+		fmt.Fprintf(w, "%s - no runs recorded\n", report.JobName)
+		return
+	}
+
+	fmt.Fprintf(w, "job=%s runs=%d success_rate=%.1f%% p50_ms=%d p95_ms=%d\n",
+		report.JobName, summary.Runs, summary.SuccessRate*100, summary.P50DurationMs, summary.P95DurationMs)
+	if summary.LastFailure != nil {
+		fmt.Fprintf(w, "last_failure=%s exit=%d start=%s\n", summary.LastFailure.ID, summary.LastFailure.ExitCode, summary.LastFailure.StartTime)
+	}
+	for _, cell := range report.Cells {
+		fmt.Fprintf(w, "cell=%s runs=%d success_rate=%.1f%% p50_ms=%d p95_ms=%d\n",
+			cell.Cell, cell.Summary.Runs, cell.Summary.SuccessRate*100, cell.Summary.P50DurationMs, cell.Summary.P95DurationMs)
+	}
+	for _, run := range report.Runs {
+		fmt.Fprintf(w, "%s status=%s exit=%d duration_ms=%d start=%s%s\n", run.ID, run.Status, run.ExitCode, run.DurationMs, run.StartTime, cellField(run.Matrix))
+	}
+}
@@ -0,0 +1,96 @@
+package app
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"orchastration/internal/outcome"
+	"orchastration/internal/state"
+)
+
+func TestBuildHistoryFiltersAndSummary(t *testing.T) {
+	entries := []state.RecordEntry{
+		{ID: "20260301T000000Z", Record: state.Record{JobName: "nightly", StartTime: "2026-03-01T00:00:00Z", DurationMs: 100}},
+		{ID: "20260302T000000Z", Record: state.Record{JobName: "nightly", StartTime: "2026-03-02T00:00:00Z", DurationMs: 400, ExitCode: 1}},
+		{ID: "20260303T000000Z", Record: state.Record{JobName: "nightly", StartTime: "2026-03-03T00:00:00Z", DurationMs: 200}},
+		{ID: "20260304T000000Z", Record: state.Record{JobName: "nightly", StartTime: "2026-03-04T00:00:00Z", DurationMs: 300}},
+		{ID: "20260305T000000Z", Record: state.Record{JobName: "nightly", StartTime: "2026-03-05T00:00:00Z", DurationMs: 900, ExitCode: 2}},
+	}
+
+	filter := historyFilter{since: time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)}
+	report := buildHistory("nightly", entries, filter)
+	if len(report.Runs) != 4 {
+		t.Fatalf("expected 4 runs, got %d", len(report.Runs))
+	}
+
+	summary := report.Summary
+	if summary.Successes != 2 || summary.Failures != 2 {
+		t.Fatalf("unexpected counts: %+v", summary)
+	}
+	if summary.SuccessRate != 0.5 {
+		t.Fatalf("unexpected success rate: %v", summary.SuccessRate)
+	}
+	if summary.P50DurationMs != 300 || summary.P95DurationMs != 900 {
+		t.Fatalf("unexpected percentiles: p50=%d p95=%d", summary.P50DurationMs, summary.P95DurationMs)
+	}
+	if summary.LastFailure == nil || summary.LastFailure.ID != "20260305T000000Z" {
+		t.Fatalf("unexpected last failure: %+v", summary.LastFailure)
+	}
+
+	failed := buildHistory("nightly", entries, historyFilter{status: "failed", limit: 1})
+	if len(failed.Runs) != 1 || failed.Runs[0].ID != "20260305T000000Z" {
+		t.Fatalf("unexpected filtered runs: %+v", failed.Runs)
+	}
+}
+
+func TestSummarizeHistoryUsesOutcome(t *testing.T) {
+	entries := []state.RecordEntry{
+		{ID: "01", Record: state.Record{StartTime: "2026-03-01T00:00:00Z", Status: "failed", Outcome: outcome.Warning, ExitCode: 1}},
+		{ID: "02", Record: state.Record{StartTime: "2026-03-02T00:00:00Z", Status: "success", Outcome: outcome.Success}},
+		{ID: "03", Record: state.Record{StartTime: "2026-03-03T00:00:00Z", Status: "failed", Outcome: outcome.Failure, ExitCode: 2}},
+		{ID: "04", Record: state.Record{StartTime: "2026-03-04T00:00:00Z", Status: "failed", ExitCode: 3}},
+	}
+
+	summary := buildHistory("nightly", entries, historyFilter{}).Summary
+	if summary.Successes != 2 || summary.Failures != 2 {
+		t.Fatalf("expected the allowed failure to count as a success, got %+v", summary)
+	}
+	if summary.LastFailure == nil || summary.LastFailure.ID != "04" {
+		t.Fatalf("unexpected last failure: %+v", summary.LastFailure)
+	}
+}
+
+func TestParseHistoryTime(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+
+	got, err := parseHistoryTime("24h", now)
+	if err != nil || !got.Equal(now.Add(-24*time.Hour)) {
+		t.Fatalf("unexpected duration parse: %v %v", got, err)
+	}
+	got, err = parseHistoryTime("2026-03-01", now)
+	if err != nil || !got.Equal(time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("unexpected date parse: %v %v", got, err)
+	}
+	if _, err := parseHistoryTime("yesterday", now); err == nil {
+		t.Fatalf("expected error for unrecognized time")
+	}
+}
+
+func TestEmitHistoryTable(t *testing.T) {
+	entries := []state.RecordEntry{
+		{ID: "01", Record: state.Record{StartTime: "2026-03-01T00:00:00Z", Status: "success", DurationMs: 5}},
+		{ID: "02", Record: state.Record{StartTime: "2026-03-02T00:00:00Z", Status: "failed", ExitCode: 1, DurationMs: 7, Matrix: map[string]string{"os": "linux"}}},
+	}
+
+	var buf bytes.Buffer
+	if err := emitHistory(output{format: outputTable, command: "history", w: &buf}, buildHistory("nightly", entries, historyFilter{})); err != nil {
+		t.Fatalf("emitHistory: %v", err)
+	}
+	expected := "RUN  STATUS   EXIT  DURATION_MS  START                 CELL\n" +
+		"01   success  0     5            2026-03-01T00:00:00Z  -\n" +
+		"02   failed   1     7            2026-03-02T00:00:00Z  os=linux\n"
+	if buf.String() != expected {
+		t.Fatalf("unexpected output:\n%s", buf.String())
+	}
+}
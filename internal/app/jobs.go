@@ -1,33 +1,84 @@
 package app
 
 import (
-	"context"
 	"errors"
+	"flag"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
+	"os/signal"
 	"path/filepath"
 	"runtime"
 	"sort"
+	"strconv"
 	"strings"
+	"syscall"
 	"time"
 
 	"orchastration/internal/config"
+	"orchastration/internal/environ"
+	"orchastration/internal/hooks"
+	"orchastration/internal/lock"
 	"orchastration/internal/logging"
+	"orchastration/internal/notify"
+	"orchastration/internal/outcome"
+	"orchastration/internal/retry"
+	"orchastration/internal/runid"
+	"orchastration/internal/runner"
+	"orchastration/internal/secrets"
 	"orchastration/internal/state"
 )
 
-func listJobs(cfg config.Config) (int, error) {
-	if len(cfg.Jobs) == 0 {
+// jobItem is the structured output shape of list.
+type jobItem struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Tags        []string `json:"tags"`
+	Schedule    string   `json:"schedule"`
+}
+
+func listJobs(args []string, cfg config.Config, out output) (int, error) {
+	fs := flag.NewFlagSet("list", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	tag := fs.String("tag", "", "only list jobs with this tag")
+	if err := fs.Parse(args); err != nil {
+		return 2, err
+	}
+
+	if len(cfg.Jobs) == 0 && !out.structured() {
 		fmt.Fprintln(os.Stdout, "no jobs configured")
 		return 0, nil
 	}
 
 	names := make([]string, 0, len(cfg.Jobs))
-	for name := range cfg.Jobs {
-		names = append(names, name)
+	for name, job := range cfg.Jobs {
+		if *tag == "" || containsString(job.Tags, *tag) {
+			names = append(names, name)
+		}
 	}
 	sort.Strings(names)
+	if out.structured() {
+		items := make([]jobItem, 0, len(names))
+		rows := make([][]string, 0, len(names))
+		for _, name := range names {
+			job := cfg.Jobs[name]
+			tags := job.Tags
+			if tags == nil {
+				tags = []string{}
+			}
+			items = append(items, jobItem{Name: name, Description: job.Description, Tags: tags, Schedule: job.Schedule})
+			rows = append(rows, []string{name, orDash(strings.Join(tags, ",")), orDash(job.Schedule), orDash(job.Description)})
+		}
+		if err := out.emit(items, []string{"NAME", "TAGS", "SCHEDULE", "DESCRIPTION"}, rows); err != nil {
+			return 2, err
+		}
+		return 0, nil
+	}
+	if len(names) == 0 {
+		fmt.Fprintf(os.Stdout, "no jobs tagged %s\n", *tag)
+		return 0, nil
+	}
 
 	for _, name := range names {
 		job := cfg.Jobs[name]
@@ -40,106 +91,526 @@ func listJobs(cfg config.Config) (int, error) {
 	return 0, nil
 }
 
-func runJob(args []string, cfg config.Config, logger *logging.Logger, stateDir string, version string) (int, error) {
-	if len(args) == 0 {
-		return 2, errors.New("run requires a job name")
+func runJob(args []string, cfg config.Config, logger *logging.Logger, stateDir string, version string, out output) (int, error) {
+	fs := flag.NewFlagSet("run", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	withDeps := fs.Bool("with-deps", false, "run the job's dependencies first")
+	all := fs.Bool("all", false, "run every configured job")
+	tag := fs.String("tag", "", "run every job with this tag")
+	workers := fs.Int("j", 1, "number of jobs to run at once")
+	tee := fs.Bool("tee", false, "mirror job output to the terminal while capturing it")
+	prefix := fs.Bool("prefix", false, "prefix mirrored output lines with [job]")
+	force := fs.Bool("force", false, "run even when the job's inputs are unchanged")
+	params := paramFlag{}
+	fs.Var(params, "param", "job parameter as key=value (repeatable)")
+	remaining, err := parseInterspersed(fs, args)
+	if err != nil {
+		return 2, err
+	}
+	if *workers < 1 {
+		return 2, errors.New("-j must be at least 1")
+	}
+
+	targets, err := selectJobs(cfg, remaining, *all, *tag)
+	if err != nil {
+		return 2, err
+	}
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(signals)
+
+	resolver := secrets.NewResolver(cfg.Secrets)
+	opts := jobOptions{
+		tee:           *tee,
+		prefix:        *prefix,
+		params:        params,
+		signals:       signals,
+		secrets:       resolver,
+		hashAlgorithm: cfg.Hash.Algorithm,
+		notifier:      notify.New(cfg.Notifications, resolver, logger),
+		force:         *force,
+		structured:    out.structured(),
 	}
+	if len(targets) == 1 && !*withDeps {
+		jobName := targets[0]
+		result, err := executeJob(jobName, cfg.Jobs[jobName], opts, logger, stateDir, version)
+		autoPrune(cfg, logger, stateDir, jobName)
+		if out.structured() {
+			if emitErr := emitBatch(out, []batchEntry{{Name: jobName, Result: result, Err: err}}); emitErr != nil {
+				return 2, emitErr
+			}
+		}
+		if err != nil {
+			return 2, err
+		}
+		return 0, nil
+	}
+
+	if err := checkBatchParams(cfg, targets, params); err != nil {
+		return 2, err
+	}
+	order := targets
+	if *withDeps {
+		if order, err = dependencyClosure(cfg.Jobs, targets); err != nil {
+			return 2, err
+		}
+	}
+
+	entries := runBatch(order, cfg, opts, *workers, logger, stateDir, version)
+	if out.structured() {
+		if err := emitBatch(out, entries); err != nil {
+			return 2, err
+		}
+	} else {
+		printBatchSummary(os.Stdout, entries)
+	}
+	for _, entry := range entries {
+		if entry.Err != nil {
+			return 2, fmt.Errorf("job %s: %w", entry.Name, entry.Err)
+		}
+	}
+	return 0, nil
+}
+
+// jobOptions carries per-invocation settings from the command line.
+type jobOptions struct {
+	tee           bool
+	prefix        bool
+	params        map[string]string
+	signals       <-chan os.Signal
+	secrets       *secrets.Resolver
+	hashAlgorithm string
+	notifier      *notify.Notifier
+	force         bool
+	// structured leaves stdout to --output: result lines are not printed
+	// and mirrored output goes to stderr.
+	structured bool
+}
 
-	jobName := args[0]
-	job, ok := cfg.Jobs[jobName]
-	if !ok {
-		return 2, fmt.Errorf("unknown job: %s", jobName)
+// printf prints a result line unless output is structured.
+func (o jobOptions) printf(format string, args ...any) {
+	if o.structured {
+		return
 	}
+	fmt.Fprintf(os.Stdout, format, args...)
+}
 
+func executeJob(jobName string, job config.JobConfig, opts jobOptions, logger *logging.Logger, stateDir string, version string) (jobResult, error) {
 	if len(job.Command) == 0 {
-		return 2, fmt.Errorf("job %s has empty command", jobName)
+		return jobResult{Status: statusError}, fmt.Errorf("job %s has empty command", jobName)
+	}
+	params, err := resolveParams(jobName, job.Params, opts.params)
+	if err != nil {
+		return jobResult{Status: statusError}, err
+	}
+
+	signals := opts.signals
+	if signals == nil {
+		ch := make(chan os.Signal, 1)
+		signal.Notify(ch, os.Interrupt, syscall.SIGTERM)
+		defer signal.Stop(ch)
+		signals = ch
+	}
+
+	held, skipped, err := acquireJobLock(jobName, job.Concurrency, logger, stateDir, signals)
+	if err != nil {
+		return jobResult{Status: statusError}, err
+	}
+	if skipped {
+		opts.printf("job=%s skipped concurrency=skip\n", jobName)
+		return jobResult{Status: statusSkipped}, nil
+	}
+	if held != nil {
+		defer func() {
+			if err := held.Release(); err != nil {
+				logger.Warn("failed to release lock", "job", jobName, "error", err)
+			}
+		}()
+		watched, stop := watchReplace(jobLockPath(stateDir, jobName), signals)
+		defer stop()
+		signals = watched
+	}
+
+	if len(job.Matrix) > 0 {
+		return runMatrix(jobName, job, params, opts, signals, logger, stateDir, version)
+	}
+	return runJobCell(jobName, job, nil, params, opts, signals, logger, stateDir, version)
+}
+
+// runJobCell runs the job once, for a single matrix cell when cell is set,
+// and writes its record.
+func runJobCell(jobName string, job config.JobConfig, cell map[string]string, params map[string]string, opts jobOptions, signals <-chan os.Signal, logger *logging.Logger, stateDir string, version string) (jobResult, error) {
+	label := jobName
+	if len(cell) > 0 {
+		label = jobName + " " + cellKey(cell)
+		logger = logger.With("cell", cellKey(cell))
+	}
+	job, err := renderJob(jobName, withMatrixEnv(job, cell), templateData{Params: params, Matrix: cell})
+	if err != nil {
+		return jobResult{Status: statusError}, err
+	}
+	policy, err := retry.NewPolicy(job.Retries, job.RetryBackoff, job.RetryOnExit)
+	if err != nil {
+		return jobResult{Status: statusError}, fmt.Errorf("job %s: %w", jobName, err)
+	}
+	exitPolicy, err := outcome.NewPolicy(job.SuccessExitCodes, job.WarningExitCodes, job.AllowFailure)
+	if err != nil {
+		return jobResult{Status: statusError}, fmt.Errorf("job %s: %w", jobName, err)
+	}
+
+	// Secrets are resolved only now that the command is about to start.
+	redactor := logger.Redactor()
+	env, err := jobEnv(job, opts.secrets, redactor)
+	if err != nil {
+		return jobResult{Status: statusError}, fmt.Errorf("job %s env: %w", jobName, err)
 	}
 
 	start := time.Now().UTC()
-	timeStamp := start.Format("20060102T150405Z")
+	runID := runid.At(start)
+	if len(cell) > 0 {
+		runID += "-" + cellSlug(cell)
+	}
 	runDir := filepath.Join(stateDir, "runs", jobName)
-	stdoutPath := filepath.Join(runDir, timeStamp+".stdout.log")
-	stderrPath := filepath.Join(runDir, timeStamp+".stderr.log")
+	stdoutPath := filepath.Join(runDir, runID+".stdout.log")
+	stderrPath := filepath.Join(runDir, runID+".stderr.log")
+	logPath := filepath.Join(runDir, runID+".log")
+	recordPath := filepath.Join(runDir, runID+".json")
+	lastPath := filepath.Join(runDir, "last.json")
+
+	var inputsDigest string
+	if len(job.Inputs) > 0 {
+		if inputsDigest, err = jobInputsDigest(job, opts.hashAlgorithm); err != nil {
+			logger.Warn("input digest failed", "job", jobName, "error", err)
+		} else if source, ok := findCachedRun(runDir, job, cell, inputsDigest); ok && !opts.force {
+			return recordCachedRun(jobName, cell, params, source, inputsDigest, start, runID, recordPath, lastPath, opts, redactor, logger, version)
+		}
+	}
 
 	if err := os.MkdirAll(runDir, 0o755); err != nil {
-		return 2, fmt.Errorf("create run dir: %w", err)
+		return jobResult{Status: statusError}, fmt.Errorf("create run dir: %w", err)
+	}
+
+	var workspace string
+	if job.Workspace == workspaceScratch {
+		if workspace, err = prepareWorkspace(job, runDir, runID); err != nil {
+			logger.Error("workspace setup failed", "job", jobName, "error", err)
+			return jobResult{Status: statusError}, fmt.Errorf("job %s: %w", jobName, err)
+		}
+		// The command, hooks, and artifact capture all work in the scratch
+		// workspace instead of working_dir.
+		job.WorkingDir = workspace
+		env = append(env, "ORCH_WORKSPACE="+workspace)
+	}
+	// discardWorkspace removes the workspace of a run that fails to start.
+	discardWorkspace := func() {
+		if workspace != "" {
+			_ = os.RemoveAll(workspace)
+		}
 	}
 
 	stdoutFile, err := os.OpenFile(stdoutPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
 	if err != nil {
-		return 2, fmt.Errorf("open stdout file: %w", err)
+		discardWorkspace()
+		return jobResult{Status: statusError}, fmt.Errorf("open stdout file: %w", err)
 	}
 	defer stdoutFile.Close()
 
 	stderrFile, err := os.OpenFile(stderrPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
 	if err != nil {
-		return 2, fmt.Errorf("open stderr file: %w", err)
+		discardWorkspace()
+		return jobResult{Status: statusError}, fmt.Errorf("open stderr file: %w", err)
 	}
 	defer stderrFile.Close()
 
-	ctx := context.Background()
-	if job.TimeoutSeconds > 0 {
-		var cancel context.CancelFunc
-		ctx, cancel = context.WithTimeout(ctx, time.Duration(job.TimeoutSeconds)*time.Second)
-		defer cancel()
+	logFile, err := os.OpenFile(logPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		discardWorkspace()
+		return jobResult{Status: statusError}, fmt.Errorf("open log file: %w", err)
 	}
+	defer logFile.Close()
 
-	cmd := exec.CommandContext(ctx, job.Command[0], job.Command[1:]...)
-	cmd.Stdout = stdoutFile
-	cmd.Stderr = stderrFile
-	if job.WorkingDir != "" {
-		cmd.Dir = job.WorkingDir
+	// Both streams also go to one log in the order they are written, which
+	// logs serves when no stream is selected.
+	combined := &lockedWriter{w: logFile}
+	var stdout, stderr io.Writer = io.MultiWriter(stdoutFile, combined), io.MultiWriter(stderrFile, combined)
+	stdoutMask, stderrMask := redactor.Writer(stdout), redactor.Writer(stderr)
+	if opts.tee || job.StreamOutput {
+		var terminal io.Writer = os.Stdout
+		if opts.structured {
+			terminal = os.Stderr
+		}
+		stdout, stderr = teeWriters(label, opts.prefix || job.StreamPrefix, stdout, stderr, terminal, os.Stderr)
+		// Mirrored output is redacted a line at a time too, but a partial
+		// line is let through once the command goes quiet.
+		stdoutMask, stderrMask = redactor.LiveWriter(stdout, teeFlushInterval), redactor.LiveWriter(stderr, teeFlushInterval)
+	}
+	stdout, stderr = stdoutMask, stderrMask
+
+	tracker, err := startRunRecord(state.Record{
+		RunID:      runID,
+		JobName:    jobName,
+		StartTime:  start.Format(time.RFC3339),
+		StdoutPath: stdoutPath,
+		StderrPath: stderrPath,
+		LogPath:    logPath,
+		OS:         runtime.GOOS,
+		Version:    version,
+		Params:     params,
+		Matrix:     cell,
+		Workspace:  workspace,
+	}.Redact(redactor.String), recordPath, lastPath)
+	if err != nil {
+		logger.Error("failed to write running record", "job", jobName, "error", err)
+		discardWorkspace()
+		return jobResult{Status: statusError}, err
 	}
-	cmd.Env = mergeEnv(job.Env)
+
+	hookRunner := hooks.Runner{
+		Hooks:   job.Hooks,
+		Dir:     job.WorkingDir,
+		Env:     env,
+		Signals: signals,
+		Grace:   time.Duration(job.KillGraceSeconds) * time.Second,
+		Stdout:  stdout,
+		Stderr:  stderr,
+	}
+	hookCtx := hooks.Context{Kind: "job", Name: jobName, RunID: runID, StdoutPath: stdoutPath, StderrPath: stderrPath}
 
 	logger.Info("job starting", "job", jobName, "command", strings.Join(job.Command, " "))
-	execErr := cmd.Run()
+	attempts := make([]state.Attempt, 0, 1)
+	var limitsHit []string
+	var execErr error
+	exitCode := 0
+	status := runner.StatusSuccess
+	hookRuns, beforeErr := hookRunner.Run(hooks.Before, hookCtx)
+	if beforeErr != nil {
+		// The command never starts; the run takes the failed hook's result.
+		last := hookRuns[len(hookRuns)-1]
+		execErr, status, exitCode = beforeErr, last.Status, last.ExitCode
+		logger.Error("job before hook failed", "job", jobName, "error", beforeErr)
+	}
+	for attempt := 1; beforeErr == nil; attempt++ {
+		attemptStart := time.Now().UTC()
+		result := runJobAttempt(job, env, signals, stdout, stderr)
+		attemptEnd := time.Now().UTC()
+		exitCode = exitCodeFromError(result.Err)
+		status, execErr = exitPolicy.Apply(result.Status, exitCode, result.Err)
+		limitsHit = mergeLimits(limitsHit, result.LimitsHit)
+		if len(result.LimitsHit) > 0 {
+			logger.Warn("job hit resource limits", "job", jobName, "attempt", attempt, "limits", strings.Join(result.LimitsHit, ","))
+		}
+		attempts = append(attempts, state.NewAttempt(attempt, attemptStart, attemptEnd, exitCode, execErr))
+		if execErr == nil {
+			break
+		}
+
+		switch status {
+		case runner.StatusTimedOut:
+			logger.Error("job timed out", "job", jobName, "attempt", attempt, "timeout_seconds", job.TimeoutSeconds)
+		case runner.StatusCancelled:
+			logger.Error("job cancelled", "job", jobName, "attempt", attempt, "error", execErr)
+		default:
+			logger.Error("job failed", "job", jobName, "attempt", attempt, "error", execErr)
+		}
+		if status == runner.StatusCancelled || !policy.ShouldRetry(attempt, exitCode) {
+			break
+		}
+		delay := policy.Backoff(attempt)
+		logger.Warn("job retrying", "job", jobName, "attempt", attempt+1, "delay_ms", delay.Milliseconds())
+		if err := runner.Sleep(delay, signals); err != nil {
+			execErr, status = fmt.Errorf("%w: %w", err, execErr), runner.StatusCancelled
+			logger.Error("job cancelled", "job", jobName, "error", err)
+			break
+		}
+	}
 
 	end := time.Now().UTC()
 	duration := end.Sub(start)
-	exitCode := exitCodeFromError(execErr)
-	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
-		logger.Error("job timed out", "job", jobName, "timeout_seconds", job.TimeoutSeconds)
+
+	var artifactsDir string
+	var artifacts []state.Artifact
+	if len(job.Artifacts) > 0 {
+		artifactsDir = filepath.Join(runDir, runID+".artifacts")
+		artifacts, err = captureArtifacts(job.Artifacts, job.WorkingDir, artifactsDir, opts.hashAlgorithm)
+		if err != nil {
+			logger.Warn("artifact capture failed", "job", jobName, "error", err)
+		}
+		if len(artifacts) == 0 {
+			artifactsDir = ""
+		}
+	}
+
+	runOutcome := exitPolicy.Outcome(status, exitCode)
+	if execErr != nil && runOutcome != outcome.Failure {
+		logger.Warn("job failure allowed", "job", jobName, "error", execErr)
 	}
-	if execErr != nil {
-		logger.Error("job failed", "job", jobName, "error", execErr)
+
+	hookCtx.Status, hookCtx.Outcome, hookCtx.ExitCode = status, runOutcome, exitCode
+	hookCtx.DurationMs = duration.Milliseconds()
+	afterRuns, err := hookRunner.After(hookCtx)
+	hookRuns = append(hookRuns, afterRuns...)
+	if err != nil {
+		logger.Warn("job hook failed", "job", jobName, "error", err)
+	}
+	if workspace != "" {
+		if keepWorkspace(job.WorkspaceRetention, runOutcome) {
+			logger.Info("workspace kept", "job", jobName, "workspace", workspace)
+		} else if err := os.RemoveAll(workspace); err != nil {
+			logger.Warn("workspace cleanup failed", "job", jobName, "workspace", workspace, "error", err)
+		} else {
+			workspace = ""
+		}
 	}
 
+	_ = stdoutMask.Close()
+	_ = stderrMask.Close()
+
 	record := state.Record{
-		JobName:    jobName,
-		StartTime:  start.Format(time.RFC3339),
-		EndTime:    end.Format(time.RFC3339),
-		DurationMs: duration.Milliseconds(),
-		ExitCode:   exitCode,
-		StdoutPath: stdoutPath,
-		StderrPath: stderrPath,
-		OS:         runtime.GOOS,
-		Version:    version,
+		RunID:        runID,
+		JobName:      jobName,
+		StartTime:    start.Format(time.RFC3339),
+		EndTime:      end.Format(time.RFC3339),
+		DurationMs:   duration.Milliseconds(),
+		ExitCode:     exitCode,
+		Status:       status,
+		Outcome:      runOutcome,
+		StdoutPath:   stdoutPath,
+		StderrPath:   stderrPath,
+		LogPath:      logPath,
+		OS:           runtime.GOOS,
+		Version:      version,
+		Attempts:     attempts,
+		Params:       params,
+		LimitsHit:    limitsHit,
+		Matrix:       cell,
+		ArtifactsDir: artifactsDir,
+		Artifacts:    artifacts,
+		Hooks:        hookRuns,
+		PID:          tracker.record.PID,
+		Host:         tracker.record.Host,
+		InputsDigest: inputsDigest,
+		Workspace:    workspace,
+	}.Redact(redactor.String)
+
+	tracker.finish()
+	if err := state.WriteRecord(recordPath, record); err != nil {
+		logger.Error("failed to write record", "job", jobName, "error", err)
+		return jobResult{Status: statusError}, err
+	}
+	if err := state.WriteRecord(lastPath, record); err != nil {
+		logger.Error("failed to write last record", "job", jobName, "error", err)
+		return jobResult{Status: statusError}, err
+	}
+	opts.notifier.Job(record)
+
+	opts.printf("job=%s%s exit=%d duration_ms=%d status=%s outcome=%s run=%s\n", jobName, cellField(cell), exitCode, duration.Milliseconds(), status, runOutcome, runID)
+	result := jobResult{Status: status, Outcome: runOutcome, ExitCode: exitCode, DurationMs: duration.Milliseconds()}
+	result.Runs = []runItem{{RunID: runID, Cell: cell, Status: status, Outcome: runOutcome, ExitCode: exitCode, DurationMs: duration.Milliseconds()}}
+	if runOutcome != outcome.Failure {
+		return result, nil
+	}
+	return result, execErr
+}
+
+// recordCachedRun writes the record of a run skipped because its inputs
+// match source, pointing at source's logs.
+func recordCachedRun(jobName string, cell map[string]string, params map[string]string, source state.RecordEntry, inputsDigest string, start time.Time, runID string, recordPath string, lastPath string, opts jobOptions, redactor *logging.Redactor, logger *logging.Logger, version string) (jobResult, error) {
+	cachedFrom := source.Record.CachedFrom
+	if cachedFrom == "" {
+		cachedFrom = source.ID
 	}
+	record := state.Record{
+		RunID:        runID,
+		JobName:      jobName,
+		StartTime:    start.Format(time.RFC3339),
+		EndTime:      start.Format(time.RFC3339),
+		Status:       state.StatusCached,
+		Outcome:      outcome.Success,
+		StdoutPath:   source.Record.StdoutPath,
+		StderrPath:   source.Record.StderrPath,
+		LogPath:      source.Record.LogPath,
+		ArtifactsDir: source.Record.ArtifactsDir,
+		Artifacts:    source.Record.Artifacts,
+		OS:           runtime.GOOS,
+		Version:      version,
+		Params:       params,
+		Matrix:       cell,
+		InputsDigest: inputsDigest,
+		CachedFrom:   cachedFrom,
+	}.Redact(redactor.String)
 
-	recordPath := filepath.Join(runDir, timeStamp+".json")
 	if err := state.WriteRecord(recordPath, record); err != nil {
 		logger.Error("failed to write record", "job", jobName, "error", err)
-		return 2, err
+		return jobResult{Status: statusError}, err
 	}
-	lastPath := filepath.Join(runDir, "last.json")
 	if err := state.WriteRecord(lastPath, record); err != nil {
 		logger.Error("failed to write last record", "job", jobName, "error", err)
-		return 2, err
+		return jobResult{Status: statusError}, err
 	}
+	logger.Info("job inputs unchanged", "job", jobName, "cached_from", cachedFrom)
+	opts.notifier.Job(record)
 
-	fmt.Fprintf(os.Stdout, "job=%s exit=%d duration_ms=%d\n", jobName, exitCode, duration.Milliseconds())
-	if execErr != nil {
-		return 2, execErr
+	opts.printf("job=%s%s exit=0 duration_ms=0 status=%s outcome=%s run=%s cached_from=%s\n", jobName, cellField(cell), state.StatusCached, outcome.Success, runID, cachedFrom)
+	run := runItem{RunID: runID, Cell: cell, Status: state.StatusCached, Outcome: outcome.Success, CachedFrom: cachedFrom}
+	return jobResult{Status: state.StatusCached, Outcome: outcome.Success, Runs: []runItem{run}}, nil
+}
+
+func runJobAttempt(job config.JobConfig, env []string, signals <-chan os.Signal, stdout io.Writer, stderr io.Writer) runner.Result {
+	cmd := exec.Command(job.Command[0], job.Command[1:]...)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	if job.WorkingDir != "" {
+		cmd.Dir = job.WorkingDir
 	}
-	return 0, nil
+	cmd.Env = env
+
+	return runner.Run(cmd, runner.Options{
+		Timeout: time.Duration(job.TimeoutSeconds) * time.Second,
+		Grace:   time.Duration(job.KillGraceSeconds) * time.Second,
+		Signals: signals,
+		Limits:  job.Limits,
+		Sandbox: job.Sandbox,
+	})
+}
+
+// mergeLimits adds newly hit limits to those seen in earlier attempts.
+func mergeLimits(seen []string, hits []string) []string {
+	for _, hit := range hits {
+		if !containsString(seen, hit) {
+			seen = append(seen, hit)
+		}
+	}
+	return seen
+}
+
+// jobStatusItem is the structured output shape of status. LastRun is nil
+// for jobs without runs and for matrix jobs, whose runs are under Cells.
+type jobStatusItem struct {
+	JobName string         `json:"job_name"`
+	LastRun *state.Record  `json:"last_run"`
+	Cells   []cellStatus   `json:"cells,omitempty"`
+	Lock    *jobStatusLock `json:"lock"`
+}
+
+type cellStatus struct {
+	Matrix  map[string]string `json:"matrix"`
+	LastRun *state.Record     `json:"last_run"`
+}
+
+type jobStatusLock struct {
+	lock.Holder
+	Stale bool `json:"stale"`
 }
 
-func jobStatus(cfg config.Config, stateDir string) (int, error) {
-	if len(cfg.Jobs) == 0 {
+func jobStatus(cfg config.Config, logger *logging.Logger, stateDir string, out output) (int, error) {
+	if len(cfg.Jobs) == 0 && !out.structured() {
 		fmt.Fprintln(os.Stdout, "no jobs configured")
 		return 0, nil
 	}
+	reconcileRuns(stateDir, logger)
+	if out.structured() {
+		return jobStatusStructured(cfg, stateDir, out)
+	}
 
 	names := make([]string, 0, len(cfg.Jobs))
 	for name := range cfg.Jobs {
@@ -148,42 +619,102 @@ func jobStatus(cfg config.Config, stateDir string) (int, error) {
 	sort.Strings(names)
 
 	for _, name := range names {
+		if matrix := cfg.Jobs[name].Matrix; len(matrix) > 0 {
+			printMatrixStatus(os.Stdout, name, matrix, stateDir)
+			continue
+		}
 		lastPath := filepath.Join(stateDir, "runs", name, "last.json")
 		record, err := state.ReadRecord(lastPath)
-		if err != nil {
-			fmt.Fprintf(os.Stdout, "%s - no runs recorded\n", name)
-			continue
+		line := fmt.Sprintf("%s - no runs recorded", name)
+		if err == nil && record.Status == state.StatusRunning {
+			line = fmt.Sprintf("%s - %s", name, runningSummary(record, time.Now()))
+		} else if err == nil {
+			line = fmt.Sprintf("%s - exit=%d duration_ms=%d start=%s", name, record.ExitCode, record.DurationMs, record.StartTime)
+			if record.Outcome != "" {
+				line += " outcome=" + record.Outcome
+			}
+			if record.Status == state.StatusAbandoned || record.Status == state.StatusCached {
+				line += " status=" + record.Status
+			}
 		}
-		fmt.Fprintf(os.Stdout, "%s - exit=%d duration_ms=%d start=%s\n", name, record.ExitCode, record.DurationMs, record.StartTime)
+		fmt.Fprintln(os.Stdout, line+lockSummary(stateDir, name))
 	}
 
 	return 0, nil
 }
 
-func mergeEnv(extra map[string]string) []string {
-	env := os.Environ()
-	if len(extra) == 0 {
-		return env
+func jobStatusStructured(cfg config.Config, stateDir string, out output) (int, error) {
+	names := make([]string, 0, len(cfg.Jobs))
+	for name := range cfg.Jobs {
+		names = append(names, name)
 	}
+	sort.Strings(names)
 
-	seen := make(map[string]struct{}, len(env))
-	for i, entry := range env {
-		parts := strings.SplitN(entry, "=", 2)
-		key := parts[0]
-		if value, ok := extra[key]; ok {
-			env[i] = key + "=" + value
+	items := make([]jobStatusItem, 0, len(names))
+	var rows [][]string
+	addRow := func(label string, record *state.Record) {
+		if record == nil {
+			rows = append(rows, []string{label, "-", "-", "-", "-", "-"})
+			return
 		}
-		seen[key] = struct{}{}
+		rows = append(rows, []string{
+			label,
+			orDash(record.Status),
+			orDash(record.Outcome),
+			strconv.Itoa(record.ExitCode),
+			strconv.FormatInt(record.DurationMs, 10),
+			record.StartTime,
+		})
 	}
-
-	for key, value := range extra {
-		if _, ok := seen[key]; ok {
-			continue
+	for _, name := range names {
+		item := jobStatusItem{JobName: name}
+		if holder, err := lock.Read(jobLockPath(stateDir, name)); err == nil {
+			item.Lock = &jobStatusLock{Holder: holder, Stale: holder.Stale()}
+		}
+		if matrix := cfg.Jobs[name].Matrix; len(matrix) > 0 {
+			latest := latestCellRecords(stateDir, name)
+			item.Cells = []cellStatus{}
+			for _, cell := range expandMatrix(matrix) {
+				entry := cellStatus{Matrix: cell}
+				if record, ok := latest[cellKey(cell)]; ok {
+					entry.LastRun = &record
+				}
+				item.Cells = append(item.Cells, entry)
+				addRow(fmt.Sprintf("%s[%s]", name, cellKey(cell)), entry.LastRun)
+			}
+		} else {
+			if record, err := state.ReadRecord(filepath.Join(stateDir, "runs", name, "last.json")); err == nil {
+				item.LastRun = &record
+			}
+			addRow(name, item.LastRun)
 		}
-		env = append(env, key+"="+value)
+		items = append(items, item)
 	}
+	if err := out.emit(items, []string{"JOB", "STATUS", "OUTCOME", "EXIT", "DURATION_MS", "START"}, rows); err != nil {
+		return 2, err
+	}
+	return 0, nil
+}
 
-	return env
+// jobEnv builds the job environment, resolving secret references and
+// registering their values with the redactor.
+func jobEnv(job config.JobConfig, resolver *secrets.Resolver, redactor *logging.Redactor) ([]string, error) {
+	inherit, allow, err := environ.ParseInherit(job.InheritEnv)
+	if err != nil {
+		return nil, err
+	}
+	env, values, err := resolver.ResolveEnv(job.Env)
+	if err != nil {
+		return nil, err
+	}
+	redactor.Add(values...)
+	return environ.Build(os.Environ(), environ.Spec{
+		Inherit: inherit,
+		Allow:   allow,
+		Files:   job.EnvFiles,
+		BaseDir: job.WorkingDir,
+		Env:     env,
+	})
 }
 
 func exitCodeFromError(err error) int {
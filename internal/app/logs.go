@@ -0,0 +1,244 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"orchastration/internal/config"
+	"orchastration/internal/runid"
+	"orchastration/internal/state"
+)
+
+const logPollInterval = 200 * time.Millisecond
+
+type logRun struct {
+	ID         string
+	StdoutPath string
+	StderrPath string
+	// LogPath holds both streams in write order; runs recorded before it
+	// existed have none.
+	LogPath    string
+	RecordPath string
+}
+
+type logStream struct {
+	path    string
+	file    *os.File
+	pending []byte
+}
+
+func runLogs(args []string, cfg config.Config, stateDir string) (int, error) {
+	fs := flag.NewFlagSet("logs", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	runID := fs.String("run", "", "run ID to show (defaults to the most recent run)")
+	stderrOnly := fs.Bool("stderr", false, "show only stderr")
+	stdoutOnly := fs.Bool("stdout", false, "show only stdout")
+	follow := fs.Bool("follow", false, "keep printing output until the run finishes")
+	positional, err := parseInterspersed(fs, args)
+	if err != nil {
+		return 2, err
+	}
+	if len(positional) == 0 {
+		return 2, errors.New("logs requires a job name")
+	}
+	if *stderrOnly && *stdoutOnly {
+		return 2, errors.New("logs accepts only one of --stdout and --stderr")
+	}
+
+	jobName := positional[0]
+	if _, ok := cfg.Jobs[jobName]; !ok {
+		return 2, fmt.Errorf("unknown job: %s", jobName)
+	}
+
+	run, err := resolveLogRun(filepath.Join(stateDir, "runs", jobName), *runID)
+	if err != nil {
+		return 2, err
+	}
+
+	paths := []string{run.StdoutPath, run.StderrPath}
+	if _, err := os.Stat(run.LogPath); run.LogPath != "" && err == nil {
+		paths = []string{run.LogPath}
+	}
+	if *stdoutOnly {
+		paths = []string{run.StdoutPath}
+	}
+	if *stderrOnly {
+		paths = []string{run.StderrPath}
+	}
+
+	if !*follow {
+		for _, path := range paths {
+			if err := copyLogFile(os.Stdout, path); err != nil {
+				return 2, err
+			}
+		}
+		return 0, nil
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if err := followLogs(ctx, os.Stdout, run.RecordPath, paths, logPollInterval); err != nil {
+		return 2, err
+	}
+	return 0, nil
+}
+
+// resolveLogRun finds the log files for a run, preferring the paths stored
+// in its record. Runs that are still in progress have no record yet.
+func resolveLogRun(runDir string, runID string) (logRun, error) {
+	if runID == "" {
+		latest, err := latestLogRunID(runDir)
+		if err != nil {
+			return logRun{}, err
+		}
+		runID = latest
+	}
+
+	run := logRun{
+		ID:         runID,
+		StdoutPath: filepath.Join(runDir, runID+".stdout.log"),
+		StderrPath: filepath.Join(runDir, runID+".stderr.log"),
+		LogPath:    filepath.Join(runDir, runID+".log"),
+		RecordPath: filepath.Join(runDir, runID+".json"),
+	}
+	if record, err := state.ReadRecord(run.RecordPath); err == nil && record.JobName != "" {
+		run.StdoutPath = record.StdoutPath
+		run.StderrPath = record.StderrPath
+		run.LogPath = record.LogPath
+		return run, nil
+	}
+
+	if _, err := os.Stat(run.StdoutPath); err != nil {
+		return logRun{}, fmt.Errorf("no logs found for run: %s", runID)
+	}
+	return run, nil
+}
+
+func latestLogRunID(runDir string) (string, error) {
+	entries, err := os.ReadDir(runDir)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return "", fmt.Errorf("read run dir: %w", err)
+	}
+
+	latest := ""
+	for _, entry := range entries {
+		id, ok := strings.CutSuffix(entry.Name(), ".stdout.log")
+		if ok && (latest == "" || runid.Less(latest, id)) {
+			latest = id
+		}
+	}
+	if latest == "" {
+		return "", errors.New("no runs recorded")
+	}
+	return latest, nil
+}
+
+// lockedWriter serialises writes from the stdout and stderr copiers into
+// the combined log.
+type lockedWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (l *lockedWriter) Write(data []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.w.Write(data)
+}
+
+func copyLogFile(w io.Writer, path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("open log: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := io.Copy(w, file); err != nil {
+		return fmt.Errorf("read log: %w", err)
+	}
+	return nil
+}
+
+// followLogs polls the log files and writes complete lines as they arrive,
+// so stdout and stderr interleave in the order they were written. It returns
+// once the run's record is final, or its process is gone, and everything has
+// been drained.
+func followLogs(ctx context.Context, w io.Writer, recordPath string, paths []string, interval time.Duration) error {
+	streams := make([]*logStream, 0, len(paths))
+	for _, path := range paths {
+		streams = append(streams, &logStream{path: path})
+	}
+	defer func() {
+		for _, stream := range streams {
+			if stream.file != nil {
+				stream.file.Close()
+			}
+		}
+	}()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		record, err := state.ReadRecord(recordPath)
+		finished := err == nil && (record.Status != state.StatusRunning || runAbandoned(record, time.Now()))
+
+		for _, stream := range streams {
+			if err := stream.drain(w, finished); err != nil {
+				return err
+			}
+		}
+		if finished {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *logStream) drain(w io.Writer, flush bool) error {
+	if s.file == nil {
+		file, err := os.Open(s.path)
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				return nil
+			}
+			return fmt.Errorf("open log: %w", err)
+		}
+		s.file = file
+	}
+
+	data, err := io.ReadAll(s.file)
+	if err != nil {
+		return fmt.Errorf("read log: %w", err)
+	}
+	s.pending = append(s.pending, data...)
+
+	if idx := bytes.LastIndexByte(s.pending, '\n'); idx >= 0 {
+		if _, err := w.Write(s.pending[:idx+1]); err != nil {
+			return err
+		}
+		s.pending = s.pending[idx+1:]
+	}
+	if flush && len(s.pending) > 0 {
+		if _, err := w.Write(s.pending); err != nil {
+			return err
+		}
+		s.pending = nil
+	}
+	return nil
+}
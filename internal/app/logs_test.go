@@ -0,0 +1,82 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"orchastration/internal/state"
+)
+
+func TestResolveLogRunLatest(t *testing.T) {
+	runDir := t.TempDir()
+	for _, name := range []string{"20260301T000000Z.stdout.log", "20260302T000000Z.stdout.log", "20260302T000000Z.stderr.log"} {
+		if err := os.WriteFile(filepath.Join(runDir, name), nil, 0o644); err != nil {
+			t.Fatalf("write log: %v", err)
+		}
+	}
+
+	run, err := resolveLogRun(runDir, "")
+	if err != nil {
+		t.Fatalf("resolveLogRun: %v", err)
+	}
+	if run.ID != "20260302T000000Z" {
+		t.Fatalf("unexpected run ID: %s", run.ID)
+	}
+	if run.StderrPath != filepath.Join(runDir, "20260302T000000Z.stderr.log") {
+		t.Fatalf("unexpected stderr path: %s", run.StderrPath)
+	}
+
+	if _, err := resolveLogRun(runDir, "20250101T000000Z"); err == nil {
+		t.Fatalf("expected error for unknown run")
+	}
+}
+
+func TestFollowLogsUntilRecordWritten(t *testing.T) {
+	runDir := t.TempDir()
+	stdoutPath := filepath.Join(runDir, "run.stdout.log")
+	stderrPath := filepath.Join(runDir, "run.stderr.log")
+	recordPath := filepath.Join(runDir, "run.json")
+
+	stdoutFile, err := os.Create(stdoutPath)
+	if err != nil {
+		t.Fatalf("create stdout: %v", err)
+	}
+	defer stdoutFile.Close()
+	stderrFile, err := os.Create(stderrPath)
+	if err != nil {
+		t.Fatalf("create stderr: %v", err)
+	}
+	defer stderrFile.Close()
+
+	var buf bytes.Buffer
+	done := make(chan error, 1)
+	go func() {
+		done <- followLogs(context.Background(), &buf, recordPath, []string{stdoutPath, stderrPath}, 10*time.Millisecond)
+	}()
+
+	stdoutFile.WriteString("first\n")
+	time.Sleep(50 * time.Millisecond)
+	stderrFile.WriteString("second\n")
+	time.Sleep(50 * time.Millisecond)
+	stdoutFile.WriteString("third")
+	if err := state.WriteRecord(recordPath, state.Record{JobName: "job"}); err != nil {
+		t.Fatalf("write record: %v", err)
+	}
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("followLogs: %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf("timeout waiting for follow to finish")
+	}
+
+	if buf.String() != "first\nsecond\nthird" {
+		t.Fatalf("unexpected output: %q", buf.String())
+	}
+}
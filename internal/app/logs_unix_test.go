@@ -0,0 +1,44 @@
+//go:build !windows
+
+package app
+
+import (
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"orchastration/internal/config"
+	"orchastration/internal/logging"
+	"orchastration/internal/secrets"
+)
+
+func TestExecuteJobWritesCombinedLogInOrder(t *testing.T) {
+	stateDir := t.TempDir()
+	job := config.JobConfig{Command: []string{"sh", "-c", "echo one; sleep 0.05; echo two >&2; sleep 0.05; echo three"}}
+	logger := &logging.Logger{Logger: slog.New(slog.NewTextHandler(io.Discard, nil))}
+	opts := jobOptions{secrets: secrets.NewResolver(config.SecretsConfig{}), hashAlgorithm: "sha256"}
+	if _, err := executeJob("build", job, opts, logger, stateDir, "test"); err != nil {
+		t.Fatalf("executeJob: %v", err)
+	}
+
+	run, err := resolveLogRun(filepath.Join(stateDir, "runs", "build"), "")
+	if err != nil {
+		t.Fatalf("resolveLogRun: %v", err)
+	}
+	if run.LogPath != filepath.Join(stateDir, "runs", "build", run.ID+".log") {
+		t.Fatalf("unexpected log path: %s", run.LogPath)
+	}
+	data, err := os.ReadFile(run.LogPath)
+	if err != nil {
+		t.Fatalf("read log: %v", err)
+	}
+	if string(data) != "one\ntwo\nthree\n" {
+		t.Fatalf("unexpected combined log: %q", data)
+	}
+	stderr, err := os.ReadFile(run.StderrPath)
+	if err != nil || string(stderr) != "two\n" {
+		t.Fatalf("unexpected stderr log: %q (%v)", stderr, err)
+	}
+}
@@ -0,0 +1,225 @@
+package app
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"orchastration/internal/config"
+	"orchastration/internal/logging"
+	"orchastration/internal/outcome"
+	"orchastration/internal/runner"
+	"orchastration/internal/state"
+)
+
+// expandMatrix returns every combination of the matrix values. Keys vary in
+// sorted order with the last key changing fastest.
+func expandMatrix(matrix map[string][]string) []map[string]string {
+	keys := sortedKeys(matrix)
+	cells := []map[string]string{{}}
+	for _, key := range keys {
+		next := make([]map[string]string, 0, len(cells)*len(matrix[key]))
+		for _, cell := range cells {
+			for _, value := range matrix[key] {
+				expanded := make(map[string]string, len(cell)+1)
+				for k, v := range cell {
+					expanded[k] = v
+				}
+				expanded[key] = value
+				next = append(next, expanded)
+			}
+		}
+		cells = next
+	}
+	return cells
+}
+
+// cellKey renders a matrix cell as key=value pairs in key order.
+func cellKey(cell map[string]string) string {
+	keys := cellKeys(cell)
+	pairs := make([]string, 0, len(keys))
+	for _, key := range keys {
+		pairs = append(pairs, key+"="+cell[key])
+	}
+	return strings.Join(pairs, ",")
+}
+
+// cellSlug makes a matrix cell safe to use in run file names. It contains
+// no dots, so retention still groups a run's files by their prefix, and
+// distinct cells always get distinct slugs.
+func cellSlug(cell map[string]string) string {
+	keys := cellKeys(cell)
+	parts := make([]string, 0, len(keys))
+	for _, key := range keys {
+		parts = append(parts, escapeSlug(key)+"-"+escapeSlug(cell[key]))
+	}
+	return strings.Join(parts, "_")
+}
+
+// escapeSlug keeps ASCII letters and digits and writes every other byte,
+// including the "-" and "_" separators, as ~XX.
+func escapeSlug(value string) string {
+	var out strings.Builder
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		if c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' {
+			out.WriteByte(c)
+			continue
+		}
+		fmt.Fprintf(&out, "~%02X", c)
+	}
+	return out.String()
+}
+
+// matrixEnvName is the variable a matrix value is exposed as, e.g.
+// MATRIX_GO_VERSION for the key go-version.
+func matrixEnvName(key string) string {
+	return "MATRIX_" + strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' {
+			return r - 'a' + 'A'
+		}
+		if r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' {
+			return r
+		}
+		return '_'
+	}, key)
+}
+
+// cellField is the " cell=..." suffix for output lines of matrix runs.
+func cellField(cell map[string]string) string {
+	if len(cell) == 0 {
+		return ""
+	}
+	return " cell=" + cellKey(cell)
+}
+
+// withMatrixEnv adds the cell's values to the job env without overriding
+// variables the job sets itself.
+func withMatrixEnv(job config.JobConfig, cell map[string]string) config.JobConfig {
+	if len(cell) == 0 {
+		return job
+	}
+	env := make(map[string]string, len(job.Env)+len(cell))
+	for key, value := range cell {
+		env[matrixEnvName(key)] = value
+	}
+	for key, value := range job.Env {
+		env[key] = value
+	}
+	job.Env = env
+	return job
+}
+
+// runMatrix runs every cell of a matrix job, at most matrix_parallelism at
+// a time. Once interrupted, no further cells are started. The result is
+// that of the first cell, in cell order, that did not succeed; otherwise the
+// outcome is a warning if any cell's was.
+func runMatrix(jobName string, job config.JobConfig, params map[string]string, opts jobOptions, signals <-chan os.Signal, logger *logging.Logger, stateDir string, version string) (jobResult, error) {
+	start := time.Now()
+	cells := expandMatrix(job.Matrix)
+	parallel := job.MatrixParallelism
+	if parallel < 1 {
+		parallel = 1
+	}
+
+	hub, stop := newSignalHub(signals)
+	defer stop()
+
+	slots := make(chan struct{}, parallel)
+	results := make([]jobResult, len(cells))
+	errs := make([]error, len(cells))
+	var wg sync.WaitGroup
+	for i, cell := range cells {
+		slots <- struct{}{}
+		if hub.cancelled() {
+			<-slots
+			results[i] = jobResult{Status: statusSkipped}
+			errs[i] = runner.ErrCancelled
+			continue
+		}
+		cellSignals, unsubscribe := hub.subscribe()
+		wg.Add(1)
+		go func(i int, cell map[string]string) {
+			defer wg.Done()
+			defer func() { <-slots }()
+			defer unsubscribe()
+			results[i], errs[i] = runJobCell(jobName, job, cell, params, opts, cellSignals, logger, stateDir, version)
+		}(i, cell)
+	}
+	wg.Wait()
+
+	overall := jobResult{Status: runner.StatusSuccess, Outcome: outcome.Success, DurationMs: time.Since(start).Milliseconds()}
+	for _, result := range results {
+		overall.Runs = append(overall.Runs, result.Runs...)
+	}
+	for i, err := range errs {
+		if err != nil {
+			overall.Status, overall.Outcome, overall.ExitCode = results[i].Status, outcome.Failure, results[i].ExitCode
+			return overall, fmt.Errorf("job %s cell %s: %w", jobName, cellKey(cells[i]), err)
+		}
+		if results[i].Outcome == outcome.Warning {
+			overall.Outcome = outcome.Warning
+		}
+	}
+	return overall, nil
+}
+
+func sortedKeys(matrix map[string][]string) []string {
+	keys := make([]string, 0, len(matrix))
+	for key := range matrix {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func cellKeys(cell map[string]string) []string {
+	keys := make([]string, 0, len(cell))
+	for key := range cell {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// latestCellRecords maps each matrix cell key of a job to its latest run.
+func latestCellRecords(stateDir string, jobName string) map[string]state.Record {
+	entries, _ := state.ListRecords(filepath.Join(stateDir, "runs", jobName))
+	latest := make(map[string]state.Record)
+	for _, entry := range entries {
+		if len(entry.Record.Matrix) > 0 {
+			latest[cellKey(entry.Record.Matrix)] = entry.Record
+		}
+	}
+	return latest
+}
+
+// printMatrixStatus shows the latest run of every configured matrix cell.
+func printMatrixStatus(w io.Writer, jobName string, matrix map[string][]string, stateDir string) {
+	cells := expandMatrix(matrix)
+	fmt.Fprintf(w, "%s - matrix cells=%d%s\n", jobName, len(cells), lockSummary(stateDir, jobName))
+
+	latest := latestCellRecords(stateDir, jobName)
+	for _, cell := range cells {
+		key := cellKey(cell)
+		record, ok := latest[key]
+		if !ok {
+			fmt.Fprintf(w, "  [%s] - no runs recorded\n", key)
+			continue
+		}
+		if record.Status == state.StatusRunning {
+			fmt.Fprintf(w, "  [%s] - %s\n", key, runningSummary(record, time.Now()))
+			continue
+		}
+		line := fmt.Sprintf("  [%s] - exit=%d duration_ms=%d start=%s", key, record.ExitCode, record.DurationMs, record.StartTime)
+		if record.Status == state.StatusAbandoned || record.Status == state.StatusCached {
+			line += " status=" + record.Status
+		}
+		fmt.Fprintln(w, line)
+	}
+}
@@ -0,0 +1,77 @@
+package app
+
+import (
+	"strings"
+	"testing"
+
+	"orchastration/internal/config"
+	"orchastration/internal/state"
+)
+
+func TestExpandMatrix(t *testing.T) {
+	cells := expandMatrix(map[string][]string{
+		"os": {"linux", "windows"},
+		"go": {"1.21", "1.22"},
+	})
+	if len(cells) != 4 {
+		t.Fatalf("expected 4 cells, got %d", len(cells))
+	}
+	want := []string{"go=1.21,os=linux", "go=1.21,os=windows", "go=1.22,os=linux", "go=1.22,os=windows"}
+	for i, cell := range cells {
+		if got := cellKey(cell); got != want[i] {
+			t.Fatalf("cell %d: expected %s, got %s", i, want[i], got)
+		}
+	}
+	if slug := cellSlug(cells[0]); slug != "go-1~2E21_os-linux" {
+		t.Fatalf("unexpected slug: %s", slug)
+	}
+}
+
+func TestCellSlugDistinct(t *testing.T) {
+	cells := []map[string]string{
+		{"go": "1.21"},
+		{"go": "1-21"},
+		{"go": "1_21"},
+		{"go-1": "21"},
+		{"go": "1", "x": "21"},
+		{"go": "1_x-21"},
+	}
+	seen := make(map[string]bool)
+	for _, cell := range cells {
+		slug := cellSlug(cell)
+		if seen[slug] || strings.Contains(slug, ".") {
+			t.Fatalf("unexpected slug %s for %v", slug, cell)
+		}
+		seen[slug] = true
+	}
+}
+
+func TestWithMatrixEnv(t *testing.T) {
+	job := config.JobConfig{Env: map[string]string{"MATRIX_GO": "pinned"}}
+	job = withMatrixEnv(job, map[string]string{"go": "1.22", "target-dir": "api"})
+	if job.Env["MATRIX_GO"] != "pinned" {
+		t.Fatalf("expected job env to win, got %s", job.Env["MATRIX_GO"])
+	}
+	if job.Env["MATRIX_TARGET_DIR"] != "api" {
+		t.Fatalf("expected MATRIX_TARGET_DIR=api, got %v", job.Env)
+	}
+}
+
+func TestBuildHistoryGroupsCells(t *testing.T) {
+	entries := []state.RecordEntry{
+		{ID: "1-go-1-21", Record: state.Record{JobName: "test", Matrix: map[string]string{"go": "1.21"}}},
+		{ID: "1-go-1-22", Record: state.Record{JobName: "test", Matrix: map[string]string{"go": "1.22"}, ExitCode: 1}},
+		{ID: "2-go-1-21", Record: state.Record{JobName: "test", Matrix: map[string]string{"go": "1.21"}}},
+	}
+
+	report := buildHistory("test", entries, historyFilter{})
+	if len(report.Cells) != 2 {
+		t.Fatalf("expected 2 cells, got %d", len(report.Cells))
+	}
+	if report.Cells[0].Cell != "go=1.21" || report.Cells[0].Summary.Runs != 2 || report.Cells[0].Summary.Successes != 2 {
+		t.Fatalf("unexpected first cell: %+v", report.Cells[0])
+	}
+	if report.Cells[1].Summary.Failures != 1 {
+		t.Fatalf("unexpected second cell: %+v", report.Cells[1])
+	}
+}
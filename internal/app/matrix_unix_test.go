@@ -0,0 +1,56 @@
+//go:build !windows
+
+package app
+
+import (
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"orchastration/internal/config"
+	"orchastration/internal/logging"
+	"orchastration/internal/secrets"
+	"orchastration/internal/state"
+)
+
+func TestRunMatrixParallelCellsKeepRecordsIntact(t *testing.T) {
+	stateDir := t.TempDir()
+	job := config.JobConfig{
+		Command:           []string{"true"},
+		Matrix:            map[string][]string{"go": {"1.21", "1-21", "1_21", "1.22"}, "os": {"linux", "windows"}},
+		MatrixParallelism: 8,
+	}
+	logger := &logging.Logger{Logger: slog.New(slog.NewTextHandler(io.Discard, nil))}
+	opts := jobOptions{secrets: secrets.NewResolver(config.SecretsConfig{}), hashAlgorithm: "sha256"}
+	if _, err := executeJob("build", job, opts, logger, stateDir, "test"); err != nil {
+		t.Fatalf("executeJob: %v", err)
+	}
+
+	runDir := filepath.Join(stateDir, "runs", "build")
+	if _, err := state.ReadRecord(filepath.Join(runDir, "last.json")); err != nil {
+		t.Fatalf("read last record: %v", err)
+	}
+	entries, err := state.ListRecords(runDir)
+	if err != nil {
+		t.Fatalf("ListRecords: %v", err)
+	}
+	cells := make(map[string]bool)
+	for _, entry := range entries {
+		cells[cellKey(entry.Record.Matrix)] = true
+	}
+	if len(cells) != 8 {
+		t.Fatalf("expected a record for each of 8 cells, got %v", cells)
+	}
+	files, err := os.ReadDir(runDir)
+	if err != nil {
+		t.Fatalf("read run dir: %v", err)
+	}
+	for _, file := range files {
+		if strings.HasSuffix(file.Name(), ".tmp") {
+			t.Fatalf("temporary record left behind: %s", file.Name())
+		}
+	}
+}
@@ -7,14 +7,17 @@ import (
 	"io"
 	"os"
 	"sort"
+	"strings"
 
 	"orchastration/internal/agent"
 	"orchastration/internal/config"
 	"orchastration/internal/logging"
+	"orchastration/internal/notify"
 	"orchastration/internal/orchestrator"
+	"orchastration/internal/secrets"
 )
 
-func runOrchestration(args []string, cfg config.Config, logger *logging.Logger, stateDir string) (int, error) {
+func runOrchestration(args []string, cfg config.Config, logger *logging.Logger, stateDir string, out output) (int, error) {
 	if len(args) == 0 {
 		return 2, errors.New("orchestration requires a subcommand")
 	}
@@ -22,7 +25,10 @@ func runOrchestration(args []string, cfg config.Config, logger *logging.Logger,
 	sub := args[0]
 	switch sub {
 	case "list":
-		return orchestrationList(os.Stdout, cfg.Orchestrations)
+		if out.structured() {
+			return orchestrationListStructured(out, cfg.Orchestrations)
+		}
+		return orchestrationList(out.w, cfg.Orchestrations)
 	case "run":
 		return orchestrationRun(args[1:], cfg, logger, stateDir)
 	default:
@@ -53,6 +59,41 @@ func orchestrationList(w io.Writer, orchestrations map[string]config.Orchestrati
 	return 0, nil
 }
 
+// orchestrationItem is the structured output shape of orchestration list.
+type orchestrationItem struct {
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	Agents      []string   `json:"agents"`
+	Steps       [][]string `json:"steps"`
+}
+
+func orchestrationListStructured(out output, orchestrations map[string]config.OrchestrationConfig) (int, error) {
+	names := make([]string, 0, len(orchestrations))
+	for name := range orchestrations {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	items := []orchestrationItem{}
+	var rows [][]string
+	for _, name := range names {
+		orch := orchestrations[name]
+		item := orchestrationItem{Name: name, Description: orch.Description, Agents: orch.Agents, Steps: orch.Steps}
+		if item.Agents == nil {
+			item.Agents = []string{}
+		}
+		if item.Steps == nil {
+			item.Steps = [][]string{}
+		}
+		items = append(items, item)
+		rows = append(rows, []string{name, orDash(strings.Join(item.Agents, ",")), orDash(orch.Description)})
+	}
+	if err := out.emit(items, []string{"NAME", "AGENTS", "DESCRIPTION"}, rows); err != nil {
+		return 2, err
+	}
+	return 0, nil
+}
+
 func orchestrationRun(args []string, cfg config.Config, logger *logging.Logger, stateDir string) (int, error) {
 	fs := flag.NewFlagSet("orchestration run", flag.ContinueOnError)
 	fs.SetOutput(io.Discard)
@@ -96,6 +137,7 @@ func orchestrationRun(args []string, cfg config.Config, logger *logging.Logger,
 	}
 
 	engine := orchestrator.NewOrchestrationEngine(nil)
+	engine.OnRecord = notify.New(cfg.Notifications, secrets.NewResolver(cfg.Secrets), logger).Orchestration
 	if err := engine.Run(name, steps, stateDir, ctx); err != nil {
 		logger.Error("orchestration failed", "orchestration", name, "error", err)
 		return 2, err
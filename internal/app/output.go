@@ -0,0 +1,136 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"text/tabwriter"
+)
+
+// OutputVersion changes whenever a structured output shape does.
+const OutputVersion = 1
+
+const (
+	outputText  = "text"
+	outputJSON  = "json"
+	outputJSONL = "jsonl"
+	outputTable = "table"
+)
+
+// output is where a command writes its results, in the format chosen with
+// the global --output flag. Commands print text themselves and hand items
+// to emit for the other formats.
+type output struct {
+	format  string
+	command string
+	w       io.Writer
+}
+
+type outputDocument struct {
+	Version int    `json:"version"`
+	Command string `json:"command"`
+	Items   any    `json:"items"`
+}
+
+type outputLine struct {
+	Version int    `json:"version"`
+	Command string `json:"command"`
+	Item    any    `json:"item"`
+}
+
+type outputErrorDocument struct {
+	Version int         `json:"version"`
+	Command string      `json:"command"`
+	Error   outputError `json:"error"`
+}
+
+type outputError struct {
+	Message  string `json:"message"`
+	ExitCode int    `json:"exit_code"`
+}
+
+// structured reports whether results go out as json, jsonl, or a table
+// rather than the command's own text.
+func (o output) structured() bool {
+	return o.format != outputText
+}
+
+// emit writes items, a slice, as one JSON document, one JSON line per
+// item, or a table of header and rows.
+func (o output) emit(items any, header []string, rows [][]string) error {
+	switch o.format {
+	case outputJSON:
+		encoder := json.NewEncoder(o.w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(outputDocument{Version: OutputVersion, Command: o.command, Items: items})
+	case outputJSONL:
+		encoder := json.NewEncoder(o.w)
+		list := reflect.ValueOf(items)
+		for i := 0; i < list.Len(); i++ {
+			if err := encoder.Encode(outputLine{Version: OutputVersion, Command: o.command, Item: list.Index(i).Interface()}); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		table := tabwriter.NewWriter(o.w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(table, strings.Join(header, "\t"))
+		for _, row := range rows {
+			fmt.Fprintln(table, strings.Join(row, "\t"))
+		}
+		return table.Flush()
+	}
+}
+
+// emitError writes err as a JSON object for json and jsonl output.
+func (o output) emitError(w io.Writer, err error, exitCode int) {
+	encoder := json.NewEncoder(w)
+	_ = encoder.Encode(outputErrorDocument{
+		Version: OutputVersion,
+		Command: o.command,
+		Error:   outputError{Message: err.Error(), ExitCode: exitCode},
+	})
+}
+
+// extractOutput removes the --output flag from anywhere before "--" in
+// args and returns the chosen format, text by default.
+func extractOutput(args []string) (string, []string, error) {
+	format := outputText
+	rest := make([]string, 0, len(args))
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			rest = append(rest, args[i:]...)
+			break
+		}
+		name, value, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		if !strings.HasPrefix(arg, "-") || name != "output" {
+			rest = append(rest, arg)
+			continue
+		}
+		if !hasValue {
+			if i+1 >= len(args) {
+				return format, nil, fmt.Errorf("--output requires a value")
+			}
+			i++
+			value = args[i]
+		}
+		switch value {
+		case outputText, outputJSON, outputJSONL, outputTable:
+			format = value
+		default:
+			return format, nil, fmt.Errorf("--output must be text, json, jsonl, or table: %s", value)
+		}
+	}
+	return format, rest, nil
+}
+
+// orDash fills empty table cells.
+func orDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
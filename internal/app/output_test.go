@@ -0,0 +1,130 @@
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"orchastration/internal/config"
+	"orchastration/internal/state"
+)
+
+func TestExtractOutput(t *testing.T) {
+	format, rest, err := extractOutput([]string{"--config", "c.toml", "status", "--output=jsonl", "--", "--output", "x"})
+	if err != nil {
+		t.Fatalf("extractOutput: %v", err)
+	}
+	if format != outputJSONL {
+		t.Fatalf("expected jsonl, got %s", format)
+	}
+	if strings.Join(rest, " ") != "--config c.toml status -- --output x" {
+		t.Fatalf("unexpected args: %v", rest)
+	}
+
+	if format, _, _ := extractOutput([]string{"list", "--output", "table"}); format != outputTable {
+		t.Fatalf("expected table, got %s", format)
+	}
+	if format, _, _ := extractOutput([]string{"list"}); format != outputText {
+		t.Fatalf("expected text by default, got %s", format)
+	}
+	if _, _, err := extractOutput([]string{"list", "--output", "xml"}); err == nil {
+		t.Fatalf("expected an error for an unknown format")
+	}
+	if _, _, err := extractOutput([]string{"list", "--output"}); err == nil {
+		t.Fatalf("expected an error for a missing format")
+	}
+}
+
+func TestOutputJSON(t *testing.T) {
+	var buf bytes.Buffer
+	out := output{format: outputJSON, command: "plan list", w: &buf}
+	items := []state.TaskRecord{{Name: "build", Status: "planned"}}
+	if err := out.emit(items, nil, nil); err != nil {
+		t.Fatalf("emit: %v", err)
+	}
+
+	var doc struct {
+		Version int                `json:"version"`
+		Command string             `json:"command"`
+		Items   []state.TaskRecord `json:"items"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("parse: %v\n%s", err, buf.String())
+	}
+	if doc.Version != OutputVersion || doc.Command != "plan list" || len(doc.Items) != 1 || doc.Items[0].Name != "build" {
+		t.Fatalf("unexpected document: %+v", doc)
+	}
+}
+
+func TestOutputJSONL(t *testing.T) {
+	var buf bytes.Buffer
+	out := output{format: outputJSONL, command: "list", w: &buf}
+	items := []jobItem{{Name: "a", Tags: []string{}}, {Name: "b", Tags: []string{"nightly"}}}
+	if err := out.emit(items, nil, nil); err != nil {
+		t.Fatalf("emit: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected one line per item, got:\n%s", buf.String())
+	}
+	var line struct {
+		Version int     `json:"version"`
+		Command string  `json:"command"`
+		Item    jobItem `json:"item"`
+	}
+	if err := json.Unmarshal([]byte(lines[1]), &line); err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if line.Version != OutputVersion || line.Command != "list" || line.Item.Name != "b" {
+		t.Fatalf("unexpected line: %+v", line)
+	}
+}
+
+func TestOutputTable(t *testing.T) {
+	var buf bytes.Buffer
+	out := output{format: outputTable, command: "list", w: &buf}
+	if err := out.emit([]jobItem{}, []string{"NAME", "TAGS"}, [][]string{{"build", "-"}, {"deploy-prod", "prod"}}); err != nil {
+		t.Fatalf("emit: %v", err)
+	}
+
+	expected := "NAME         TAGS\nbuild        -\ndeploy-prod  prod\n"
+	if buf.String() != expected {
+		t.Fatalf("unexpected output:\n%s", buf.String())
+	}
+}
+
+func TestOutputError(t *testing.T) {
+	var buf bytes.Buffer
+	out := output{format: outputJSON, command: "plan status"}
+	out.emitError(&buf, errors.New("unknown task: x"), 2)
+
+	expected := `{"version":1,"command":"plan status","error":{"message":"unknown task: x","exit_code":2}}` + "\n"
+	if buf.String() != expected {
+		t.Fatalf("unexpected output:\n%s", buf.String())
+	}
+}
+
+func TestPlanListStructuredWithoutState(t *testing.T) {
+	var buf bytes.Buffer
+	cfg := config.Config{Tasks: map[string]config.TaskConfig{
+		"docs":  {Description: "write docs"},
+		"build": {Status: "ready"},
+	}}
+	out := output{format: outputJSON, command: "plan list", w: &buf}
+	if _, err := planList(cfg, t.TempDir(), out); err != nil {
+		t.Fatalf("planList: %v", err)
+	}
+
+	var doc struct {
+		Items []state.TaskRecord `json:"items"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if len(doc.Items) != 2 || doc.Items[0].Name != "build" || doc.Items[0].Status != "ready" || doc.Items[1].Status != "planned" {
+		t.Fatalf("unexpected items: %+v", doc.Items)
+	}
+}
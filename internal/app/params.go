@@ -0,0 +1,139 @@
+package app
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"text/template"
+
+	"orchastration/internal/config"
+)
+
+// paramFlag collects repeated --param key=value flags.
+type paramFlag map[string]string
+
+func (p paramFlag) String() string {
+	keys := make([]string, 0, len(p))
+	for key := range p {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	pairs := make([]string, 0, len(keys))
+	for _, key := range keys {
+		pairs = append(pairs, key+"="+p[key])
+	}
+	return strings.Join(pairs, ",")
+}
+
+func (p paramFlag) Set(value string) error {
+	key, val, ok := strings.Cut(value, "=")
+	if !ok || key == "" {
+		return fmt.Errorf("param must be key=value: %s", value)
+	}
+	p[key] = val
+	return nil
+}
+
+// templateData is the value commands, env, and working_dir are rendered with.
+type templateData struct {
+	Params map[string]string
+	Matrix map[string]string
+}
+
+// resolveParams applies defaults and validates given values against the
+// job's declared params.
+func resolveParams(jobName string, declared []config.ParamConfig, given map[string]string) (map[string]string, error) {
+	known := make(map[string]bool, len(declared))
+	for _, param := range declared {
+		known[param.Name] = true
+	}
+	for key := range given {
+		if !known[key] {
+			return nil, fmt.Errorf("job %s has no param: %s", jobName, key)
+		}
+	}
+
+	resolved := make(map[string]string, len(declared))
+	for _, param := range declared {
+		value, ok := given[param.Name]
+		if !ok {
+			if param.Required {
+				return nil, fmt.Errorf("job %s requires param: %s", jobName, param.Name)
+			}
+			value = param.Default
+		}
+		if len(param.Allowed) > 0 && !containsString(param.Allowed, value) {
+			return nil, fmt.Errorf("job %s param %s must be one of %s: %s", jobName, param.Name, strings.Join(param.Allowed, ", "), value)
+		}
+		resolved[param.Name] = value
+	}
+	return resolved, nil
+}
+
+// declaredParams keeps only the given values a job declares, so params
+// passed for the target of --with-deps do not fail its dependencies.
+func declaredParams(declared []config.ParamConfig, given map[string]string) map[string]string {
+	filtered := make(map[string]string, len(given))
+	for _, param := range declared {
+		if value, ok := given[param.Name]; ok {
+			filtered[param.Name] = value
+		}
+	}
+	return filtered
+}
+
+// renderJob returns a copy of the job with templates in command, env, and
+// working_dir expanded.
+func renderJob(jobName string, job config.JobConfig, data templateData) (config.JobConfig, error) {
+	rendered := job
+	rendered.Command = make([]string, len(job.Command))
+	for i, arg := range job.Command {
+		value, err := renderTemplate(jobName, "command", arg, data)
+		if err != nil {
+			return job, err
+		}
+		rendered.Command[i] = value
+	}
+
+	if len(job.Env) > 0 {
+		rendered.Env = make(map[string]string, len(job.Env))
+		for key, raw := range job.Env {
+			value, err := renderTemplate(jobName, "env "+key, raw, data)
+			if err != nil {
+				return job, err
+			}
+			rendered.Env[key] = value
+		}
+	}
+
+	workingDir, err := renderTemplate(jobName, "working_dir", job.WorkingDir, data)
+	if err != nil {
+		return job, err
+	}
+	rendered.WorkingDir = workingDir
+	return rendered, nil
+}
+
+func renderTemplate(jobName string, field string, text string, data templateData) (string, error) {
+	if !strings.Contains(text, "{{") {
+		return text, nil
+	}
+	tmpl, err := template.New(field).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("job %s %s template: %w", jobName, field, err)
+	}
+	var out strings.Builder
+	if err := tmpl.Execute(&out, data); err != nil {
+		return "", fmt.Errorf("job %s %s template: %w", jobName, field, err)
+	}
+	return out.String(), nil
+}
+
+func containsString(values []string, target string) bool {
+	for _, value := range values {
+		if value == target {
+			return true
+		}
+	}
+	return false
+}
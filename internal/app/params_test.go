@@ -0,0 +1,62 @@
+package app
+
+import (
+	"testing"
+
+	"orchastration/internal/config"
+)
+
+func TestResolveParams(t *testing.T) {
+	declared := []config.ParamConfig{
+		{Name: "env", Default: "staging", Allowed: []string{"staging", "prod"}},
+		{Name: "target", Required: true},
+	}
+
+	params, err := resolveParams("deploy", declared, map[string]string{"target": "api"})
+	if err != nil {
+		t.Fatalf("resolveParams: %v", err)
+	}
+	if params["env"] != "staging" || params["target"] != "api" {
+		t.Fatalf("unexpected params: %v", params)
+	}
+
+	invalid := []map[string]string{
+		{},
+		{"target": "api", "env": "dev"},
+		{"target": "api", "region": "eu"},
+	}
+	for _, given := range invalid {
+		if _, err := resolveParams("deploy", declared, given); err == nil {
+			t.Fatalf("expected error for %v", given)
+		}
+	}
+}
+
+func TestRenderJob(t *testing.T) {
+	job := config.JobConfig{
+		Command:    []string{"deploy", "--env={{ .Params.env }}", "plain"},
+		WorkingDir: "/srv/{{ .Params.env }}",
+		Env:        map[string]string{"TARGET": "{{ .Params.env }}-api"},
+	}
+
+	rendered, err := renderJob("deploy", job, templateData{Params: map[string]string{"env": "prod"}})
+	if err != nil {
+		t.Fatalf("renderJob: %v", err)
+	}
+	if rendered.Command[1] != "--env=prod" || rendered.Command[2] != "plain" {
+		t.Fatalf("unexpected command: %v", rendered.Command)
+	}
+	if rendered.WorkingDir != "/srv/prod" {
+		t.Fatalf("unexpected working dir: %s", rendered.WorkingDir)
+	}
+	if rendered.Env["TARGET"] != "prod-api" {
+		t.Fatalf("unexpected env: %v", rendered.Env)
+	}
+	if job.Command[1] != "--env={{ .Params.env }}" {
+		t.Fatalf("renderJob modified the original command")
+	}
+
+	if _, err := renderJob("deploy", job, templateData{Params: map[string]string{}}); err == nil {
+		t.Fatalf("expected error for missing param")
+	}
+}
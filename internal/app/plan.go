@@ -13,7 +13,7 @@ import (
 	"orchastration/internal/taskflow"
 )
 
-func runPlan(args []string, cfg config.Config, logger *logging.Logger, stateDir string) (int, error) {
+func runPlan(args []string, cfg config.Config, logger *logging.Logger, stateDir string, out output) (int, error) {
 	if len(args) == 0 {
 		return 2, errors.New("plan requires a subcommand")
 	}
@@ -21,17 +21,20 @@ func runPlan(args []string, cfg config.Config, logger *logging.Logger, stateDir
 	sub := args[0]
 	switch sub {
 	case "list":
-		return planList(cfg, stateDir)
+		return planList(cfg, stateDir, out)
 	case "create":
 		return planCreate(args[1:], cfg, logger, stateDir)
 	case "status":
-		return planStatus(args[1:], cfg, stateDir)
+		return planStatus(args[1:], cfg, stateDir, out)
 	default:
 		return 2, fmt.Errorf("unknown plan subcommand: %s", sub)
 	}
 }
 
-func planList(cfg config.Config, stateDir string) (int, error) {
+func planList(cfg config.Config, stateDir string, out output) (int, error) {
+	if out.structured() {
+		return planListStructured(cfg, stateDir, out)
+	}
 	if len(cfg.Tasks) == 0 {
 		fmt.Fprintln(os.Stdout, "no tasks configured")
 		return 0, nil
@@ -63,6 +66,58 @@ func planList(cfg config.Config, stateDir string) (int, error) {
 	return 0, nil
 }
 
+func planListStructured(cfg config.Config, stateDir string, out output) (int, error) {
+	names := make([]string, 0, len(cfg.Tasks))
+	for name := range cfg.Tasks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	items := []state.TaskRecord{}
+	for _, name := range names {
+		items = append(items, taskItem(name, cfg.Tasks[name], stateDir))
+	}
+	return emitTasks(out, items)
+}
+
+// taskItem is the task's state record, or one built from its config when
+// the task has not been initialized.
+func taskItem(name string, task config.TaskConfig, stateDir string) state.TaskRecord {
+	record, err := state.ReadTask(filepath.Join(stateDir, "tasks", name+".json"))
+	if err == nil {
+		if record.Name == "" {
+			record.Name = name
+		}
+		if record.Status == "" {
+			record.Status = "planned"
+		}
+		return record
+	}
+	status := task.Status
+	if status == "" {
+		status = "planned"
+	}
+	return state.TaskRecord{
+		Name:        name,
+		Description: task.Description,
+		Repo:        task.Repo,
+		Status:      status,
+		Outputs:     task.Outputs,
+		Documents:   task.Documents,
+	}
+}
+
+func emitTasks(out output, items []state.TaskRecord) (int, error) {
+	rows := make([][]string, 0, len(items))
+	for _, item := range items {
+		rows = append(rows, []string{item.Name, item.Status, orDash(item.LastRun), orDash(item.Description)})
+	}
+	if err := out.emit(items, []string{"NAME", "STATUS", "LAST_RUN", "DESCRIPTION"}, rows); err != nil {
+		return 2, err
+	}
+	return 0, nil
+}
+
 func planCreate(args []string, cfg config.Config, logger *logging.Logger, stateDir string) (int, error) {
 	if len(args) == 0 {
 		return 2, errors.New("plan create requires a task name")
@@ -72,7 +127,7 @@ func planCreate(args []string, cfg config.Config, logger *logging.Logger, stateD
 	return taskflow.PlanCreate(name, cfg, logger, stateDir, os.Stdout)
 }
 
-func planStatus(args []string, cfg config.Config, stateDir string) (int, error) {
+func planStatus(args []string, cfg config.Config, stateDir string, out output) (int, error) {
 	if len(args) == 0 {
 		return 2, errors.New("plan status requires a task name")
 	}
@@ -87,6 +142,12 @@ func planStatus(args []string, cfg config.Config, stateDir string) (int, error)
 	if err != nil {
 		return 2, fmt.Errorf("task not initialized: %s", name)
 	}
+	if out.structured() {
+		if record.Name == "" {
+			record.Name = name
+		}
+		return emitTasks(out, []state.TaskRecord{record})
+	}
 
 	fmt.Fprintf(os.Stdout, "%s status=%s last_run=%s\n", name, record.Status, record.LastRun)
 	return 0, nil
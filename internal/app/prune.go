@@ -0,0 +1,155 @@
+package app
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+
+	"orchastration/internal/config"
+	"orchastration/internal/logging"
+	"orchastration/internal/retention"
+)
+
+type pruneTarget struct {
+	dir    string
+	policy config.RetentionConfig
+}
+
+// pruneItem is one run in the structured output of prune.
+type pruneItem struct {
+	Dir     string `json:"dir"`
+	RunID   string `json:"run_id"`
+	Bytes   int64  `json:"bytes"`
+	Removed bool   `json:"removed"`
+}
+
+func runPrune(args []string, cfg config.Config, logger *logging.Logger, stateDir string, out output) (int, error) {
+	fs := flag.NewFlagSet("prune", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	dryRun := fs.Bool("dry-run", false, "show what would be removed without deleting")
+	if err := fs.Parse(args); err != nil {
+		return 2, err
+	}
+
+	targets, err := pruneTargets(cfg, stateDir)
+	if err != nil {
+		return 2, err
+	}
+
+	now := time.Now()
+	verb := "removed"
+	if *dryRun {
+		verb = "would remove"
+	}
+	var runs int
+	var freed int64
+	items := make([]pruneItem, 0)
+	for _, target := range targets {
+		expired, err := pruneDir(target, now, *dryRun)
+		if err != nil {
+			logger.Error("prune failed", "dir", target.dir, "error", err)
+			return 2, err
+		}
+		rel, _ := filepath.Rel(stateDir, target.dir)
+		for _, run := range expired {
+			items = append(items, pruneItem{Dir: filepath.ToSlash(rel), RunID: run.ID, Bytes: run.Bytes, Removed: !*dryRun})
+			if !out.structured() {
+				fmt.Fprintf(os.Stdout, "%s %s/%s bytes=%d\n", verb, filepath.ToSlash(rel), run.ID, run.Bytes)
+			}
+			runs++
+			freed += run.Bytes
+		}
+	}
+
+	if !*dryRun {
+		logger.Info("prune completed", "runs", runs, "bytes", freed)
+	}
+	if out.structured() {
+		rows := make([][]string, 0, len(items))
+		for _, item := range items {
+			rows = append(rows, []string{item.Dir, item.RunID, strconv.FormatInt(item.Bytes, 10), strconv.FormatBool(item.Removed)})
+		}
+		if err := out.emit(items, []string{"DIR", "RUN", "BYTES", "REMOVED"}, rows); err != nil {
+			return 2, err
+		}
+		return 0, nil
+	}
+	fmt.Fprintf(os.Stdout, "%s runs=%d bytes=%d\n", verb, runs, freed)
+	return 0, nil
+}
+
+// pruneTargets lists every run directory in the state dir with the policy
+// that applies to it.
+func pruneTargets(cfg config.Config, stateDir string) ([]pruneTarget, error) {
+	targets := make([]pruneTarget, 0)
+	kinds := []string{"runs", "task-runs", "orchestrations"}
+	for _, kind := range kinds {
+		entries, err := os.ReadDir(filepath.Join(stateDir, kind))
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			return nil, fmt.Errorf("read %s dir: %w", kind, err)
+		}
+
+		names := make([]string, 0, len(entries))
+		for _, entry := range entries {
+			if entry.IsDir() {
+				names = append(names, entry.Name())
+			}
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			policy := cfg.Retention
+			switch kind {
+			case "runs":
+				policy = retention.Effective(cfg.Retention, cfg.Jobs[name].Retention)
+			case "orchestrations":
+				policy = retention.Effective(cfg.Retention, cfg.Orchestrations[name].Retention)
+			}
+			targets = append(targets, pruneTarget{dir: filepath.Join(stateDir, kind, name), policy: policy})
+		}
+	}
+	return targets, nil
+}
+
+func pruneDir(target pruneTarget, now time.Time, dryRun bool) ([]retention.Run, error) {
+	if !retention.Enabled(target.policy) {
+		return nil, nil
+	}
+	runs, err := retention.ListRuns(target.dir)
+	if err != nil {
+		return nil, err
+	}
+	expired := retention.Select(runs, target.policy, now)
+	if dryRun {
+		return expired, nil
+	}
+	return expired, retention.Remove(expired)
+}
+
+// autoPrune applies a job's retention policy after it runs when auto_prune
+// is enabled. Failures are logged and never fail the run itself.
+func autoPrune(cfg config.Config, logger *logging.Logger, stateDir string, jobName string) {
+	policy := retention.Effective(cfg.Retention, cfg.Jobs[jobName].Retention)
+	if !policy.AutoPrune {
+		return
+	}
+
+	target := pruneTarget{dir: filepath.Join(stateDir, "runs", jobName), policy: policy}
+	expired, err := pruneDir(target, time.Now(), false)
+	if err != nil {
+		logger.Error("auto prune failed", "job", jobName, "error", err)
+		return
+	}
+	if len(expired) > 0 {
+		logger.Info("auto prune completed", "job", jobName, "runs", len(expired))
+	}
+}
@@ -0,0 +1,160 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"orchastration/internal/lock"
+	"orchastration/internal/logging"
+	"orchastration/internal/outcome"
+	"orchastration/internal/state"
+)
+
+// heartbeatInterval is how often a running record's heartbeat is renewed.
+// Running records on other hosts are abandoned once their heartbeat is
+// abandonAfter heartbeats old, since their PID cannot be checked.
+var (
+	heartbeatInterval = 30 * time.Second
+	abandonAfter      = 10
+)
+
+// runTracker keeps the running record of a job run alive until the final
+// record replaces it.
+type runTracker struct {
+	path   string
+	record state.Record
+	stop   chan struct{}
+	wg     sync.WaitGroup
+}
+
+// startRunRecord writes a running record to the run's record path and to
+// last.json, then renews its heartbeat in the run's record until stopped.
+func startRunRecord(record state.Record, recordPath string, lastPath string) (*runTracker, error) {
+	holder := lock.Current(time.Now())
+	record.Status = state.StatusRunning
+	record.PID = holder.PID
+	record.Host = holder.Host
+	record.Heartbeat = time.Now().UTC().Format(time.RFC3339)
+	if err := state.WriteRecord(recordPath, record); err != nil {
+		return nil, err
+	}
+	if err := state.WriteRecord(lastPath, record); err != nil {
+		return nil, err
+	}
+
+	t := &runTracker{path: recordPath, record: record, stop: make(chan struct{})}
+	t.wg.Add(1)
+	go t.beat()
+	return t, nil
+}
+
+func (t *runTracker) beat() {
+	defer t.wg.Done()
+	ticker := time.NewTicker(heartbeatInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-t.stop:
+			return
+		case now := <-ticker.C:
+			t.record.Heartbeat = now.UTC().Format(time.RFC3339)
+			_ = state.WriteRecord(t.path, t.record)
+		}
+	}
+}
+
+// finish stops the heartbeat so the final record is not overwritten.
+func (t *runTracker) finish() {
+	if t == nil {
+		return
+	}
+	close(t.stop)
+	t.wg.Wait()
+}
+
+// runAbandoned reports whether a running record's process is gone: a dead
+// PID on this host, or a heartbeat too old elsewhere.
+func runAbandoned(record state.Record, now time.Time) bool {
+	if record.Status != state.StatusRunning {
+		return false
+	}
+	host, _ := os.Hostname()
+	if record.Host == host {
+		return lock.Holder{PID: record.PID, Host: record.Host}.Stale()
+	}
+	beat, err := time.Parse(time.RFC3339, record.Heartbeat)
+	if err != nil {
+		return false
+	}
+	return now.Sub(beat) > time.Duration(abandonAfter)*heartbeatInterval
+}
+
+// reconcileRuns marks every running job record whose process is gone as
+// abandoned, ending it at its last heartbeat, and returns how many it
+// marked.
+func reconcileRuns(stateDir string, logger *logging.Logger) int {
+	root := filepath.Join(stateDir, "runs")
+	dirs, err := os.ReadDir(root)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			logger.Warn("reconcile runs failed", "error", err)
+		}
+		return 0
+	}
+
+	now := time.Now()
+	marked := 0
+	for _, dir := range dirs {
+		if !dir.IsDir() {
+			continue
+		}
+		runDir := filepath.Join(root, dir.Name())
+		entries, err := state.ListRecords(runDir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			if !runAbandoned(entry.Record, now) {
+				continue
+			}
+			record := abandonRecord(entry.Record)
+			if err := state.WriteRecord(entry.Path, record); err != nil {
+				logger.Warn("reconcile runs failed", "job", record.JobName, "run", entry.ID, "error", err)
+				continue
+			}
+			lastPath := filepath.Join(runDir, "last.json")
+			if last, err := state.ReadRecord(lastPath); err == nil && last.Status == state.StatusRunning && last.RunID == record.RunID {
+				_ = state.WriteRecord(lastPath, record)
+			}
+			logger.Warn("job run abandoned", "job", record.JobName, "run", entry.ID, "pid", record.PID, "host", record.Host)
+			marked++
+		}
+	}
+	return marked
+}
+
+func abandonRecord(record state.Record) state.Record {
+	record.Status = state.StatusAbandoned
+	record.Outcome = outcome.Failure
+	record.EndTime = record.Heartbeat
+	if start, err := time.Parse(time.RFC3339, record.StartTime); err == nil {
+		if end, err := time.Parse(time.RFC3339, record.EndTime); err == nil {
+			record.DurationMs = end.Sub(start).Milliseconds()
+		}
+	}
+	record.ExitCode = -1
+	return record
+}
+
+// runningSummary describes a running record for status.
+func runningSummary(record state.Record, now time.Time) string {
+	elapsed := "?"
+	if start, err := time.Parse(time.RFC3339, record.StartTime); err == nil {
+		elapsed = now.Sub(start).Truncate(time.Second).String()
+	}
+	return fmt.Sprintf("running elapsed=%s pid=%d host=%s start=%s", elapsed, record.PID, record.Host, record.StartTime)
+}
@@ -0,0 +1,69 @@
+//go:build !windows
+
+package app
+
+import (
+	"io"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"orchastration/internal/logging"
+	"orchastration/internal/state"
+)
+
+func TestReconcileRunsMarksDeadRunsAbandoned(t *testing.T) {
+	stateDir := t.TempDir()
+	runDir := filepath.Join(stateDir, "runs", "nightly")
+	host, _ := os.Hostname()
+
+	exited := exec.Command("true")
+	if err := exited.Run(); err != nil {
+		t.Fatalf("run true: %v", err)
+	}
+
+	dead := state.Record{
+		RunID:     "01DEAD",
+		JobName:   "nightly",
+		Status:    state.StatusRunning,
+		StartTime: "2026-03-01T00:00:00Z",
+		Heartbeat: "2026-03-01T00:01:00Z",
+		PID:       exited.Process.Pid,
+		Host:      host,
+	}
+	live := dead
+	live.RunID = "01LIVE"
+	live.PID = os.Getpid()
+	for _, record := range []state.Record{dead, live} {
+		if err := state.WriteRecord(filepath.Join(runDir, record.RunID+".json"), record); err != nil {
+			t.Fatalf("write record: %v", err)
+		}
+	}
+	if err := state.WriteRecord(filepath.Join(runDir, "last.json"), dead); err != nil {
+		t.Fatalf("write last record: %v", err)
+	}
+
+	logger := &logging.Logger{Logger: slog.New(slog.NewTextHandler(io.Discard, nil))}
+	if marked := reconcileRuns(stateDir, logger); marked != 1 {
+		t.Fatalf("expected 1 abandoned run, got %d", marked)
+	}
+
+	for _, path := range []string{"01DEAD.json", "last.json"} {
+		record, err := state.ReadRecord(filepath.Join(runDir, path))
+		if err != nil {
+			t.Fatalf("read %s: %v", path, err)
+		}
+		if record.Status != state.StatusAbandoned || record.EndTime != dead.Heartbeat || record.DurationMs != 60000 {
+			t.Fatalf("%s: unexpected record %+v", path, record)
+		}
+	}
+	record, err := state.ReadRecord(filepath.Join(runDir, "01LIVE.json"))
+	if err != nil {
+		t.Fatalf("read live record: %v", err)
+	}
+	if record.Status != state.StatusRunning {
+		t.Fatalf("expected live run to stay running, got %s", record.Status)
+	}
+}
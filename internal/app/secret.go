@@ -0,0 +1,125 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"orchastration/internal/config"
+	"orchastration/internal/logging"
+	"orchastration/internal/secrets"
+)
+
+func runSecret(args []string, cfg config.Config, logger *logging.Logger) (int, error) {
+	if len(args) == 0 {
+		return 2, errors.New("secret requires a subcommand")
+	}
+
+	sub := args[0]
+	switch sub {
+	case "keygen":
+		key, err := secrets.GenerateKey()
+		if err != nil {
+			return 2, err
+		}
+		fmt.Fprintln(os.Stdout, key)
+		return 0, nil
+	case "list":
+		return secretList(cfg)
+	case "set":
+		return secretSet(args[1:], cfg, logger, os.Stdin)
+	case "rm":
+		return secretRemove(args[1:], cfg, logger)
+	default:
+		return 2, fmt.Errorf("unknown secret subcommand: %s", sub)
+	}
+}
+
+func secretList(cfg config.Config) (int, error) {
+	_, values, err := openSecrets(cfg)
+	if err != nil {
+		return 2, err
+	}
+	if len(values) == 0 {
+		fmt.Fprintln(os.Stdout, "no secrets stored")
+		return 0, nil
+	}
+
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintln(os.Stdout, name)
+	}
+	return 0, nil
+}
+
+// secretSet stores a secret read from stdin so the value never appears in
+// shell history or the process list.
+func secretSet(args []string, cfg config.Config, logger *logging.Logger, stdin io.Reader) (int, error) {
+	if len(args) == 0 {
+		return 2, errors.New("secret set requires a name")
+	}
+	name := args[0]
+
+	key, values, err := openSecrets(cfg)
+	if err != nil {
+		return 2, err
+	}
+	data, err := io.ReadAll(stdin)
+	if err != nil {
+		return 2, fmt.Errorf("read secret value: %w", err)
+	}
+	value := strings.TrimRight(string(data), "\r\n")
+	if value == "" {
+		return 2, errors.New("secret value is empty")
+	}
+
+	values[name] = value
+	if err := secrets.WriteFile(cfg.Secrets.File, key, values); err != nil {
+		return 2, err
+	}
+	logger.Info("secret stored", "name", name, "file", cfg.Secrets.File)
+	fmt.Fprintf(os.Stdout, "secret=%s stored\n", name)
+	return 0, nil
+}
+
+func secretRemove(args []string, cfg config.Config, logger *logging.Logger) (int, error) {
+	if len(args) == 0 {
+		return 2, errors.New("secret rm requires a name")
+	}
+	name := args[0]
+
+	key, values, err := openSecrets(cfg)
+	if err != nil {
+		return 2, err
+	}
+	if _, ok := values[name]; !ok {
+		return 2, fmt.Errorf("unknown secret: %s", name)
+	}
+
+	delete(values, name)
+	if err := secrets.WriteFile(cfg.Secrets.File, key, values); err != nil {
+		return 2, err
+	}
+	logger.Info("secret removed", "name", name, "file", cfg.Secrets.File)
+	fmt.Fprintf(os.Stdout, "secret=%s removed\n", name)
+	return 0, nil
+}
+
+func openSecrets(cfg config.Config) ([]byte, map[string]string, error) {
+	key, err := secrets.LoadKey(cfg.Secrets)
+	if err != nil {
+		return nil, nil, err
+	}
+	values, err := secrets.ReadFile(cfg.Secrets.File, key)
+	if err != nil {
+		return nil, nil, err
+	}
+	return key, values, nil
+}
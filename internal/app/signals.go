@@ -0,0 +1,66 @@
+package app
+
+import (
+	"os"
+	"sync"
+)
+
+// signalHub fans interrupts out to every run started in parallel, so one
+// Ctrl-C cancels them all.
+type signalHub struct {
+	mu       sync.Mutex
+	subs     map[chan os.Signal]struct{}
+	received os.Signal
+	done     chan struct{}
+}
+
+func newSignalHub(in <-chan os.Signal) (*signalHub, func()) {
+	h := &signalHub{subs: make(map[chan os.Signal]struct{}), done: make(chan struct{})}
+	go func() {
+		for {
+			select {
+			case <-h.done:
+				return
+			case sig := <-in:
+				h.broadcast(sig)
+			}
+		}
+	}()
+	return h, func() { close(h.done) }
+}
+
+func (h *signalHub) broadcast(sig os.Signal) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.received = sig
+	for ch := range h.subs {
+		select {
+		case ch <- sig:
+		default:
+		}
+	}
+}
+
+// subscribe returns a channel receiving every later signal. A signal that
+// already arrived is delivered immediately.
+func (h *signalHub) subscribe() (<-chan os.Signal, func()) {
+	ch := make(chan os.Signal, 1)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.received != nil {
+		ch <- h.received
+	}
+	h.subs[ch] = struct{}{}
+	return ch, func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		delete(h.subs, ch)
+	}
+}
+
+// cancelled reports whether a signal has arrived.
+func (h *signalHub) cancelled() bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.received != nil
+}
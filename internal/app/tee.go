@@ -0,0 +1,84 @@
+package app
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// teeFlushInterval is how long mirrored output may sit in an unterminated
+// line before it is shown anyway.
+const teeFlushInterval = 100 * time.Millisecond
+
+// prefixWriter writes a fixed prefix at the start of every line so that
+// mirrored output from several jobs stays attributable.
+type prefixWriter struct {
+	mu      sync.Mutex
+	w       io.Writer
+	prefix  []byte
+	midLine bool
+}
+
+func newPrefixWriter(w io.Writer, prefix string) *prefixWriter {
+	return &prefixWriter{w: w, prefix: []byte(prefix)}
+}
+
+func (p *prefixWriter) Write(data []byte) (int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var buf bytes.Buffer
+	for _, line := range bytes.SplitAfter(data, []byte("\n")) {
+		if len(line) == 0 {
+			continue
+		}
+		if !p.midLine {
+			buf.Write(p.prefix)
+		}
+		buf.Write(line)
+		p.midLine = line[len(line)-1] != '\n'
+	}
+	if _, err := p.w.Write(buf.Bytes()); err != nil {
+		return 0, err
+	}
+	return len(data), nil
+}
+
+// terminalWriter mirrors output on a best-effort basis: once a write fails,
+// for example because the reader of a pipe went away, it stops writing and
+// keeps reporting success so the capture files are still written in full.
+type terminalWriter struct {
+	mu     sync.Mutex
+	w      io.Writer
+	failed bool
+}
+
+func (t *terminalWriter) Write(data []byte) (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if !t.failed {
+		if _, err := t.w.Write(data); err != nil {
+			t.failed = true
+		}
+	}
+	return len(data), nil
+}
+
+// teeWriters mirrors captured output to the terminal, leaving the capture
+// files untouched even when the terminal stops accepting output.
+func teeWriters(jobName string, prefix bool, stdoutFile io.Writer, stderrFile io.Writer, stdout io.Writer, stderr io.Writer) (io.Writer, io.Writer) {
+	// A closed pipe on stdout or stderr would otherwise kill the process
+	// with SIGPIPE before the run is recorded. Notify, unlike Ignore, turns
+	// it into EPIPE without the job's commands inheriting an ignored SIGPIPE.
+	signal.Notify(make(chan os.Signal, 1), syscall.SIGPIPE)
+	if prefix {
+		stdout = newPrefixWriter(stdout, "["+jobName+"] ")
+		stderr = newPrefixWriter(stderr, "["+jobName+"] ")
+	}
+	return io.MultiWriter(stdoutFile, &terminalWriter{w: stdout}), io.MultiWriter(stderrFile, &terminalWriter{w: stderr})
+}
@@ -0,0 +1,54 @@
+package app
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestTeeWritersPrefixLeavesCaptureUnchanged(t *testing.T) {
+	var stdoutFile, stderrFile, terminalOut, terminalErr bytes.Buffer
+	stdout, stderr := teeWriters("build", true, &stdoutFile, &stderrFile, &terminalOut, &terminalErr)
+
+	stdout.Write([]byte("one\ntw"))
+	stdout.Write([]byte("o\nthree"))
+	stderr.Write([]byte("oops\n"))
+
+	if stdoutFile.String() != "one\ntwo\nthree" {
+		t.Fatalf("unexpected captured stdout: %q", stdoutFile.String())
+	}
+	if stderrFile.String() != "oops\n" {
+		t.Fatalf("unexpected captured stderr: %q", stderrFile.String())
+	}
+	if terminalOut.String() != "[build] one\n[build] two\n[build] three" {
+		t.Fatalf("unexpected terminal stdout: %q", terminalOut.String())
+	}
+	if terminalErr.String() != "[build] oops\n" {
+		t.Fatalf("unexpected terminal stderr: %q", terminalErr.String())
+	}
+}
+
+type failingWriter struct{ writes int }
+
+func (f *failingWriter) Write(data []byte) (int, error) {
+	f.writes++
+	return 0, errors.New("broken pipe")
+}
+
+func TestTeeWritersKeepCapturingWhenTerminalFails(t *testing.T) {
+	var stdoutFile, stderrFile bytes.Buffer
+	terminal := &failingWriter{}
+	stdout, _ := teeWriters("build", false, &stdoutFile, &stderrFile, terminal, terminal)
+
+	for _, chunk := range []string{"one\n", "two\n", "three\n"} {
+		if n, err := stdout.Write([]byte(chunk)); err != nil || n != len(chunk) {
+			t.Fatalf("write %q: n=%d err=%v", chunk, n, err)
+		}
+	}
+	if stdoutFile.String() != "one\ntwo\nthree\n" {
+		t.Fatalf("unexpected captured stdout: %q", stdoutFile.String())
+	}
+	if terminal.writes != 1 {
+		t.Fatalf("expected the terminal to be dropped after its first failure, got %d writes", terminal.writes)
+	}
+}
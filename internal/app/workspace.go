@@ -0,0 +1,87 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"orchastration/internal/config"
+	"orchastration/internal/digest"
+	"orchastration/internal/outcome"
+)
+
+const workspaceScratch = "scratch"
+
+// prepareWorkspace creates the scratch workspace of a run next to its
+// record, so pruning the run removes it too, and seeds it from the job's
+// working_dir.
+func prepareWorkspace(job config.JobConfig, runDir string, runID string) (string, error) {
+	dir, err := filepath.Abs(filepath.Join(runDir, runID+".workspace"))
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", fmt.Errorf("create workspace: %w", err)
+	}
+	if err := seedWorkspace(job.WorkspaceSeed, job.WorkspaceSeedMode, job.WorkingDir, dir); err != nil {
+		_ = os.RemoveAll(dir)
+		return "", err
+	}
+	return dir, nil
+}
+
+// seedWorkspace copies or hard-links the files matching patterns, relative
+// to workingDir, into dir at the same relative paths. Links fall back to
+// copies wherever linking fails, such as across filesystems.
+func seedWorkspace(patterns []string, mode string, workingDir string, dir string) error {
+	files, err := digest.Files(patterns, workingDir)
+	if err != nil {
+		return fmt.Errorf("seed workspace: %w", err)
+	}
+	base := workingDir
+	if base == "" {
+		base = "."
+	}
+	for _, rel := range files {
+		if rel == ".." || strings.HasPrefix(rel, "../") || filepath.IsAbs(rel) {
+			return fmt.Errorf("seed workspace: %s is outside working_dir", rel)
+		}
+		src := filepath.Join(base, filepath.FromSlash(rel))
+		dest := filepath.Join(dir, filepath.FromSlash(rel))
+		if mode == "link" {
+			if err := os.MkdirAll(filepath.Dir(dest), 0o755); err != nil {
+				return fmt.Errorf("seed workspace: %w", err)
+			}
+			// Any link failure falls back to a copy: crossing filesystems
+			// is EXDEV on Unix but ERROR_NOT_SAME_DEVICE on Windows, and
+			// filesystems without hard links fail in their own ways.
+			var linkErr *os.LinkError
+			err := os.Link(src, dest)
+			if err == nil {
+				continue
+			}
+			if !errors.As(err, &linkErr) {
+				return fmt.Errorf("seed workspace: %w", err)
+			}
+		}
+		if err := copyFile(src, dest); err != nil {
+			return fmt.Errorf("seed workspace: %s: %w", rel, err)
+		}
+	}
+	return nil
+}
+
+// keepWorkspace applies workspace_retention: on_failure (the default)
+// keeps the workspace of failed runs only.
+func keepWorkspace(retention string, runOutcome string) bool {
+	switch retention {
+	case "always":
+		return true
+	case "never":
+		return false
+	default:
+		return runOutcome == outcome.Failure
+	}
+}
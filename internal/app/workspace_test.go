@@ -0,0 +1,62 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"orchastration/internal/config"
+	"orchastration/internal/outcome"
+)
+
+func TestPrepareWorkspaceSeeds(t *testing.T) {
+	work := t.TempDir()
+	runDir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(work, "src", "pkg"), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	for _, rel := range []string{"src/pkg/a.txt", "config.ini", "unused.log"} {
+		if err := os.WriteFile(filepath.Join(work, filepath.FromSlash(rel)), []byte(rel), 0o644); err != nil {
+			t.Fatalf("write %s: %v", rel, err)
+		}
+	}
+
+	for _, mode := range []string{"copy", "link"} {
+		job := config.JobConfig{WorkingDir: work, WorkspaceSeed: []string{"src", "*.ini"}, WorkspaceSeedMode: mode}
+		dir, err := prepareWorkspace(job, runDir, "01RUN-"+mode)
+		if err != nil {
+			t.Fatalf("%s: prepareWorkspace: %v", mode, err)
+		}
+		if filepath.Base(dir) != "01RUN-"+mode+".workspace" {
+			t.Fatalf("%s: unexpected workspace %s", mode, dir)
+		}
+		for _, rel := range []string{"src/pkg/a.txt", "config.ini"} {
+			data, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(rel)))
+			if err != nil || string(data) != rel {
+				t.Fatalf("%s: expected %s seeded, got %q (%v)", mode, rel, data, err)
+			}
+		}
+		if _, err := os.Stat(filepath.Join(dir, "unused.log")); err == nil {
+			t.Fatalf("%s: expected unused.log not to be seeded", mode)
+		}
+	}
+}
+
+func TestKeepWorkspace(t *testing.T) {
+	cases := []struct {
+		retention string
+		outcome   string
+		want      bool
+	}{
+		{"", outcome.Failure, true},
+		{"", outcome.Success, false},
+		{"on_failure", outcome.Warning, false},
+		{"always", outcome.Success, true},
+		{"never", outcome.Failure, false},
+	}
+	for _, tc := range cases {
+		if got := keepWorkspace(tc.retention, tc.outcome); got != tc.want {
+			t.Fatalf("keepWorkspace(%q, %q) = %v, want %v", tc.retention, tc.outcome, got, tc.want)
+		}
+	}
+}
@@ -2,16 +2,26 @@ package config
 
 import (
 	"errors"
+	"fmt"
+	"net/url"
 	"os"
+	"sort"
+	"time"
 
 	"github.com/pelletier/go-toml/v2"
+
+	"orchastration/internal/environ"
+	"orchastration/internal/schedule"
 )
 
 type Config struct {
-	Logging LoggingConfig `toml:"logging"`
-	Hash    HashConfig    `toml:"hash"`
-	Jobs    map[string]JobConfig `toml:"jobs"`
-	Tasks   map[string]TaskConfig `toml:"tasks"`
+	Logging        LoggingConfig                  `toml:"logging"`
+	Hash           HashConfig                     `toml:"hash"`
+	Retention      RetentionConfig                `toml:"retention"`
+	Secrets        SecretsConfig                  `toml:"secrets"`
+	Notifications  NotificationsConfig            `toml:"notifications"`
+	Jobs           map[string]JobConfig           `toml:"jobs"`
+	Tasks          map[string]TaskConfig          `toml:"tasks"`
 	Agents         map[string]AgentConfig         `toml:"agents"`
 	Orchestrations map[string]OrchestrationConfig `toml:"orchestrations"`
 }
@@ -24,30 +34,150 @@ type HashConfig struct {
 	Algorithm string `toml:"algorithm"`
 }
 
+// SecretsConfig locates the encrypted secrets file used by secret:NAME
+// references and the key that unlocks it.
+type SecretsConfig struct {
+	File    string `toml:"file"`
+	KeyEnv  string `toml:"key_env"`
+	KeyFile string `toml:"key_file"`
+}
+
+// NotificationsConfig lists the webhooks told about finished runs.
+type NotificationsConfig struct {
+	Webhooks []WebhookConfig `toml:"webhooks"`
+}
+
+// WebhookConfig is one webhook target. Empty Events or Statuses match
+// everything; Secret may be a secret reference.
+type WebhookConfig struct {
+	Name           string        `toml:"name"`
+	URL            string        `toml:"url"`
+	Events         []string      `toml:"events"`
+	Statuses       []string      `toml:"statuses"`
+	Secret         string        `toml:"secret"`
+	Retries        int           `toml:"retries"`
+	RetryBackoff   BackoffConfig `toml:"retry_backoff"`
+	TimeoutSeconds int           `toml:"timeout_seconds"`
+}
+
 type JobConfig struct {
-	Description    string            `toml:"description"`
-	Command        []string          `toml:"command"`
-	WorkingDir     string            `toml:"working_dir"`
-	TimeoutSeconds int               `toml:"timeout_seconds"`
-	Env            map[string]string `toml:"env"`
+	Description        string              `toml:"description"`
+	Tags               []string            `toml:"tags"`
+	Artifacts          []string            `toml:"artifacts"`
+	Inputs             []string            `toml:"inputs"`
+	Command            []string            `toml:"command"`
+	WorkingDir         string              `toml:"working_dir"`
+	Workspace          string              `toml:"workspace"`
+	WorkspaceSeed      []string            `toml:"workspace_seed"`
+	WorkspaceSeedMode  string              `toml:"workspace_seed_mode"`
+	WorkspaceRetention string              `toml:"workspace_retention"`
+	TimeoutSeconds     int                 `toml:"timeout_seconds"`
+	KillGraceSeconds   int                 `toml:"kill_grace_seconds"`
+	Env                map[string]string   `toml:"env"`
+	EnvFiles           []string            `toml:"env_files"`
+	InheritEnv         any                 `toml:"inherit_env"`
+	DependsOn          []string            `toml:"depends_on"`
+	Retries            int                 `toml:"retries"`
+	RetryBackoff       BackoffConfig       `toml:"retry_backoff"`
+	RetryOnExit        []int               `toml:"retry_on_exit_codes"`
+	SuccessExitCodes   []int               `toml:"success_exit_codes"`
+	WarningExitCodes   []int               `toml:"warning_exit_codes"`
+	AllowFailure       bool                `toml:"allow_failure"`
+	Schedule           string              `toml:"schedule"`
+	Timezone           string              `toml:"timezone"`
+	CatchUp            bool                `toml:"catch_up"`
+	StreamOutput       bool                `toml:"stream_output"`
+	StreamPrefix       bool                `toml:"stream_prefix"`
+	Retention          RetentionConfig     `toml:"retention"`
+	Params             []ParamConfig       `toml:"params"`
+	Limits             LimitsConfig        `toml:"limits"`
+	Sandbox            SandboxConfig       `toml:"sandbox"`
+	Hooks              HooksConfig         `toml:"hooks"`
+	Concurrency        string              `toml:"concurrency"`
+	Matrix             map[string][]string `toml:"matrix"`
+	MatrixParallelism  int                 `toml:"matrix_parallelism"`
+}
+
+type ParamConfig struct {
+	Name     string   `toml:"name"`
+	Default  string   `toml:"default"`
+	Required bool     `toml:"required"`
+	Allowed  []string `toml:"allowed"`
 }
 
 type TaskConfig struct {
-	Description string   `toml:"description"`
-	Repo        string   `toml:"repo"`
-	WorkingDir  string   `toml:"working_dir"`
-	Command     []string `toml:"command"`
-	Outputs     []string `toml:"outputs"`
-	Documents   []string `toml:"documents"`
-	Status      string   `toml:"status"`
+	Description      string            `toml:"description"`
+	Repo             string            `toml:"repo"`
+	WorkingDir       string            `toml:"working_dir"`
+	Command          []string          `toml:"command"`
+	Outputs          []string          `toml:"outputs"`
+	Inputs           []string          `toml:"inputs"`
+	Documents        []string          `toml:"documents"`
+	Status           string            `toml:"status"`
+	Env              map[string]string `toml:"env"`
+	EnvFiles         []string          `toml:"env_files"`
+	InheritEnv       any               `toml:"inherit_env"`
+	KillGraceSeconds int               `toml:"kill_grace_seconds"`
+	Retries          int               `toml:"retries"`
+	RetryBackoff     BackoffConfig     `toml:"retry_backoff"`
+	RetryOnExit      []int             `toml:"retry_on_exit_codes"`
+	SuccessExitCodes []int             `toml:"success_exit_codes"`
+	WarningExitCodes []int             `toml:"warning_exit_codes"`
+	AllowFailure     bool              `toml:"allow_failure"`
+	Limits           LimitsConfig      `toml:"limits"`
+	Sandbox          SandboxConfig     `toml:"sandbox"`
+	Hooks            HooksConfig       `toml:"hooks"`
+}
+
+// HooksConfig lists commands run around a job or task build. Each entry is
+// an argv-style command.
+type HooksConfig struct {
+	Before         [][]string `toml:"before"`
+	OnSuccess      [][]string `toml:"on_success"`
+	OnFailure      [][]string `toml:"on_failure"`
+	Always         [][]string `toml:"always"`
+	TimeoutSeconds int        `toml:"timeout_seconds"`
+}
+
+// LimitsConfig caps the resources of a job or task command. Zero means
+// unlimited.
+type LimitsConfig struct {
+	MaxMemoryBytes int64 `toml:"max_memory_bytes"`
+	CPUSeconds     int64 `toml:"cpu_seconds"`
+	OpenFiles      int64 `toml:"open_files"`
+	MaxProcs       int64 `toml:"max_procs"`
+	MaxOutputBytes int64 `toml:"max_output_bytes"`
+}
+
+// SandboxConfig runs a job or task command in new user, mount, PID, and
+// network namespaces. Only the working directory and Writable stay
+// writable.
+type SandboxConfig struct {
+	Enabled  bool     `toml:"enabled"`
+	Writable []string `toml:"writable"`
+}
+
+type RetentionConfig struct {
+	KeepLast      int   `toml:"keep_last"`
+	MaxAgeDays    int   `toml:"max_age_days"`
+	MaxTotalBytes int64 `toml:"max_total_bytes"`
+	AutoPrune     bool  `toml:"auto_prune"`
+}
+
+type BackoffConfig struct {
+	Strategy   string `toml:"strategy"`
+	DelayMs    int    `toml:"delay_ms"`
+	MaxDelayMs int    `toml:"max_delay_ms"`
+	Jitter     bool   `toml:"jitter"`
 }
 
 type AgentConfig struct{}
 
 type OrchestrationConfig struct {
-	Agents      []string `toml:"agents"`
-	Steps       [][]string `toml:"steps"`
-	Description string   `toml:"description"`
+	Agents      []string        `toml:"agents"`
+	Steps       [][]string      `toml:"steps"`
+	Description string          `toml:"description"`
+	Retention   RetentionConfig `toml:"retention"`
 }
 
 func Default() Config {
@@ -79,5 +209,193 @@ func Load(path string) (Config, error) {
 		cfg.Hash.Algorithm = "sha256"
 	}
 
+	if err := validateJobDeps(cfg.Jobs); err != nil {
+		return cfg, err
+	}
+	if err := validateSchedules(cfg.Jobs); err != nil {
+		return cfg, err
+	}
+	if err := validateInheritEnv(cfg); err != nil {
+		return cfg, err
+	}
+	if err := validateConcurrency(cfg.Jobs); err != nil {
+		return cfg, err
+	}
+	if err := validateMatrix(cfg.Jobs); err != nil {
+		return cfg, err
+	}
+	if err := validateWorkspace(cfg.Jobs); err != nil {
+		return cfg, err
+	}
+	if err := validateHooks(cfg); err != nil {
+		return cfg, err
+	}
+	if err := validateNotifications(cfg.Notifications); err != nil {
+		return cfg, err
+	}
+
 	return cfg, nil
 }
+
+// sortedJobNames and sortedTaskNames keep validation errors stable when
+// several entries are invalid.
+func sortedJobNames(jobs map[string]JobConfig) []string {
+	names := make([]string, 0, len(jobs))
+	for name := range jobs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func sortedTaskNames(tasks map[string]TaskConfig) []string {
+	names := make([]string, 0, len(tasks))
+	for name := range tasks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// validateSchedules checks schedule and timezone up front, so a broken
+// config is not only rejected once the daemon starts.
+func validateSchedules(jobs map[string]JobConfig) error {
+	for _, name := range sortedJobNames(jobs) {
+		job := jobs[name]
+		if job.Schedule != "" {
+			if _, err := schedule.Parse(job.Schedule, job.Timezone); err != nil {
+				return fmt.Errorf("job %s schedule: %w", name, err)
+			}
+			continue
+		}
+		if job.Timezone != "" {
+			if _, err := time.LoadLocation(job.Timezone); err != nil {
+				return fmt.Errorf("job %s: invalid timezone %q: %w", name, job.Timezone, err)
+			}
+		}
+	}
+	return nil
+}
+
+func validateInheritEnv(cfg Config) error {
+	for _, name := range sortedJobNames(cfg.Jobs) {
+		if _, _, err := environ.ParseInherit(cfg.Jobs[name].InheritEnv); err != nil {
+			return fmt.Errorf("job %s: %w", name, err)
+		}
+	}
+	for _, name := range sortedTaskNames(cfg.Tasks) {
+		if _, _, err := environ.ParseInherit(cfg.Tasks[name].InheritEnv); err != nil {
+			return fmt.Errorf("task %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+func validateConcurrency(jobs map[string]JobConfig) error {
+	for _, name := range sortedJobNames(jobs) {
+		job := jobs[name]
+		switch job.Concurrency {
+		case "", "allow", "skip", "queue", "replace":
+		default:
+			return fmt.Errorf("job %s: concurrency must be allow, skip, queue, or replace: %s", name, job.Concurrency)
+		}
+	}
+	return nil
+}
+
+func validateMatrix(jobs map[string]JobConfig) error {
+	for _, name := range sortedJobNames(jobs) {
+		matrix := jobs[name].Matrix
+		keys := make([]string, 0, len(matrix))
+		for key := range matrix {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			if len(matrix[key]) == 0 {
+				return fmt.Errorf("job %s: matrix %s has no values", name, key)
+			}
+		}
+	}
+	return nil
+}
+
+func validateWorkspace(jobs map[string]JobConfig) error {
+	for _, name := range sortedJobNames(jobs) {
+		job := jobs[name]
+		switch job.Workspace {
+		case "", "scratch":
+		default:
+			return fmt.Errorf("job %s: workspace must be scratch: %s", name, job.Workspace)
+		}
+		switch job.WorkspaceSeedMode {
+		case "", "copy", "link":
+		default:
+			return fmt.Errorf("job %s: workspace_seed_mode must be copy or link: %s", name, job.WorkspaceSeedMode)
+		}
+		switch job.WorkspaceRetention {
+		case "", "on_failure", "always", "never":
+		default:
+			return fmt.Errorf("job %s: workspace_retention must be on_failure, always, or never: %s", name, job.WorkspaceRetention)
+		}
+	}
+	return nil
+}
+
+func validateHooks(cfg Config) error {
+	for _, name := range sortedJobNames(cfg.Jobs) {
+		if err := checkHooks(cfg.Jobs[name].Hooks); err != nil {
+			return fmt.Errorf("job %s: %w", name, err)
+		}
+	}
+	for _, name := range sortedTaskNames(cfg.Tasks) {
+		if err := checkHooks(cfg.Tasks[name].Hooks); err != nil {
+			return fmt.Errorf("task %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+func checkHooks(hooks HooksConfig) error {
+	stages := []struct {
+		name     string
+		commands [][]string
+	}{
+		{"before", hooks.Before},
+		{"on_success", hooks.OnSuccess},
+		{"on_failure", hooks.OnFailure},
+		{"always", hooks.Always},
+	}
+	for _, stage := range stages {
+		for _, command := range stage.commands {
+			if len(command) == 0 {
+				return fmt.Errorf("hooks.%s has an empty command", stage.name)
+			}
+		}
+	}
+	return nil
+}
+
+func validateNotifications(cfg NotificationsConfig) error {
+	for i, hook := range cfg.Webhooks {
+		name := hook.Name
+		if name == "" {
+			name = fmt.Sprintf("#%d", i+1)
+		}
+		parsed, err := url.Parse(hook.URL)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return fmt.Errorf("webhook %s: url must be an http or https URL: %q", name, hook.URL)
+		}
+		for _, event := range hook.Events {
+			switch event {
+			case "job.completed", "task.completed", "orchestration.completed":
+			default:
+				return fmt.Errorf("webhook %s: unknown event: %s", name, event)
+			}
+		}
+		if hook.Retries < 0 {
+			return fmt.Errorf("webhook %s: retries must not be negative: %d", name, hook.Retries)
+		}
+	}
+	return nil
+}
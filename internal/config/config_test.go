@@ -0,0 +1,65 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func loadConfig(t *testing.T, data string) (Config, error) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.toml")
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	return Load(path)
+}
+
+func TestLoadRejectsInvalidSchedule(t *testing.T) {
+	cases := map[string]string{
+		"schedule": `
+[jobs.nightly]
+command = ["true"]
+schedule = "61 * * * *"
+`,
+		"timezone": `
+[jobs.nightly]
+command = ["true"]
+schedule = "0 2 * * *"
+timezone = "Mars/Olympus"
+`,
+		"timezone without schedule": `
+[jobs.nightly]
+command = ["true"]
+timezone = "Mars/Olympus"
+`,
+	}
+	for name, data := range cases {
+		if _, err := loadConfig(t, data); err == nil || !strings.Contains(err.Error(), "job nightly") {
+			t.Fatalf("%s: expected a nightly error, got %v", name, err)
+		}
+	}
+}
+
+func TestLoadReportsFirstInvalidJob(t *testing.T) {
+	data := `
+[jobs.delta]
+command = ["true"]
+concurrency = "sometimes"
+
+[jobs.alpha]
+command = ["true"]
+concurrency = "sometimes"
+
+[jobs.charlie]
+command = ["true"]
+concurrency = "sometimes"
+`
+	for i := 0; i < 20; i++ {
+		_, err := loadConfig(t, data)
+		if err == nil || !strings.HasPrefix(err.Error(), "job alpha:") {
+			t.Fatalf("expected the error for alpha, got %v", err)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// JobDependencyOrder returns the transitive dependency closure of a job in
+// topological order, ending with the job itself.
+func JobDependencyOrder(jobs map[string]JobConfig, name string) ([]string, error) {
+	if _, ok := jobs[name]; !ok {
+		return nil, fmt.Errorf("unknown job: %s", name)
+	}
+
+	order := make([]string, 0)
+	visited := make(map[string]bool)
+	var visit func(string, []string) error
+	visit = func(current string, path []string) error {
+		for _, seen := range path {
+			if seen == current {
+				return fmt.Errorf("job dependency cycle: %s", strings.Join(append(path, current), " -> "))
+			}
+		}
+		if visited[current] {
+			return nil
+		}
+
+		job, ok := jobs[current]
+		if !ok {
+			return fmt.Errorf("job %s depends on unknown job: %s", path[len(path)-1], current)
+		}
+		for _, dep := range job.DependsOn {
+			if err := visit(dep, append(path, current)); err != nil {
+				return err
+			}
+		}
+
+		visited[current] = true
+		order = append(order, current)
+		return nil
+	}
+
+	if err := visit(name, nil); err != nil {
+		return nil, err
+	}
+	return order, nil
+}
+
+func validateJobDeps(jobs map[string]JobConfig) error {
+	for _, name := range sortedJobNames(jobs) {
+		if _, err := JobDependencyOrder(jobs, name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestJobDependencyOrder(t *testing.T) {
+	jobs := map[string]JobConfig{
+		"deploy": {DependsOn: []string{"test", "build"}},
+		"test":   {DependsOn: []string{"build"}},
+		"build":  {DependsOn: []string{"fetch"}},
+		"fetch":  {},
+		"other":  {},
+	}
+
+	order, err := JobDependencyOrder(jobs, "deploy")
+	if err != nil {
+		t.Fatalf("JobDependencyOrder: %v", err)
+	}
+
+	expected := []string{"fetch", "build", "test", "deploy"}
+	if strings.Join(order, ",") != strings.Join(expected, ",") {
+		t.Fatalf("expected order %v, got %v", expected, order)
+	}
+}
+
+func TestJobDependencyOrderUnknownDependency(t *testing.T) {
+	jobs := map[string]JobConfig{
+		"deploy": {DependsOn: []string{"missing"}},
+	}
+
+	if _, err := JobDependencyOrder(jobs, "deploy"); err == nil {
+		t.Fatalf("expected unknown dependency error")
+	}
+}
+
+func TestLoadRejectsDependencyCycle(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.toml")
+	data := `
+[jobs.a]
+command = ["true"]
+depends_on = ["b"]
+
+[jobs.b]
+command = ["true"]
+depends_on = ["c"]
+
+[jobs.c]
+command = ["true"]
+depends_on = ["a"]
+`
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+
+	_, err := Load(path)
+	if err == nil {
+		t.Fatalf("expected cycle error")
+	}
+	if !strings.Contains(err.Error(), "cycle") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
@@ -1,4 +1,5 @@
-package app
+// Package digest hashes files and the inputs of jobs and tasks.
+package digest
 
 import (
 	"crypto/sha1"
@@ -12,14 +13,15 @@ import (
 	"strings"
 )
 
-func hashFile(path string, algorithm string) (string, error) {
+// File returns the hex digest of the file at path.
+func File(path string, algorithm string) (string, error) {
 	file, err := os.Open(path)
 	if err != nil {
 		return "", fmt.Errorf("open file: %w", err)
 	}
 	defer file.Close()
 
-	hasher, err := selectHasher(algorithm)
+	hasher, err := Hasher(algorithm)
 	if err != nil {
 		return "", err
 	}
@@ -31,7 +33,8 @@ func hashFile(path string, algorithm string) (string, error) {
 	return hex.EncodeToString(hasher.Sum(nil)), nil
 }
 
-func selectHasher(algorithm string) (hash.Hash, error) {
+// Hasher returns a new hash for algorithm: sha256, sha1, or sha512.
+func Hasher(algorithm string) (hash.Hash, error) {
 	switch strings.ToLower(algorithm) {
 	case "sha256":
 		return sha256.New(), nil
@@ -0,0 +1,139 @@
+package digest
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Spec describes what a job or task run depends on. Relative patterns and
+// env files are resolved against Dir.
+type Spec struct {
+	Dir      string
+	Patterns []string
+	Command  []string
+	Env      map[string]string
+	EnvFiles []string
+}
+
+// Inputs returns the combined digest of the files matching the spec's
+// patterns, the command, and the configured environment, as
+// "<algorithm>:<hex>". Files are keyed by their path relative to Dir, so
+// the digest does not depend on where the working directory lives.
+func Inputs(spec Spec, algorithm string) (string, error) {
+	hasher, err := Hasher(algorithm)
+	if err != nil {
+		return "", err
+	}
+	base := baseDir(spec.Dir)
+
+	files, err := Files(spec.Patterns, spec.Dir)
+	if err != nil {
+		return "", err
+	}
+	for _, rel := range files {
+		sum, err := File(resolve(base, filepath.FromSlash(rel)), algorithm)
+		if err != nil {
+			return "", fmt.Errorf("input %s: %w", rel, err)
+		}
+		fmt.Fprintf(hasher, "file %q %s\n", rel, sum)
+	}
+
+	for _, arg := range spec.Command {
+		fmt.Fprintf(hasher, "arg %q\n", arg)
+	}
+
+	keys := make([]string, 0, len(spec.Env))
+	for key := range spec.Env {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		fmt.Fprintf(hasher, "env %q %q\n", key, spec.Env[key])
+	}
+
+	for _, file := range spec.EnvFiles {
+		sum, err := File(resolve(base, file), algorithm)
+		if errors.Is(err, os.ErrNotExist) {
+			sum = "missing"
+		} else if err != nil {
+			return "", fmt.Errorf("env file %s: %w", file, err)
+		}
+		fmt.Fprintf(hasher, "env_file %q %s\n", file, sum)
+	}
+
+	return strings.ToLower(algorithm) + ":" + hex.EncodeToString(hasher.Sum(nil)), nil
+}
+
+// Files lists the regular files matching patterns as sorted slash paths
+// relative to dir. A pattern that matches a directory includes every file
+// below it; a pattern that matches nothing adds nothing.
+func Files(patterns []string, dir string) ([]string, error) {
+	base := baseDir(dir)
+	seen := make(map[string]bool)
+	for _, pattern := range patterns {
+		matches, err := filepath.Glob(resolve(base, pattern))
+		if err != nil {
+			return nil, fmt.Errorf("input pattern %q: %w", pattern, err)
+		}
+		for _, match := range matches {
+			err := filepath.WalkDir(match, func(path string, entry fs.DirEntry, err error) error {
+				if err != nil {
+					return err
+				}
+				info, err := os.Stat(path)
+				if err != nil || !info.Mode().IsRegular() {
+					return err
+				}
+				rel, err := filepath.Rel(base, path)
+				if err != nil {
+					rel = path
+				}
+				seen[filepath.ToSlash(rel)] = true
+				return nil
+			})
+			if err != nil {
+				return nil, fmt.Errorf("input pattern %q: %w", pattern, err)
+			}
+		}
+	}
+
+	files := make([]string, 0, len(seen))
+	for rel := range seen {
+		files = append(files, rel)
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// Exist reports whether every pattern, relative to dir, still matches at
+// least one path.
+func Exist(patterns []string, dir string) bool {
+	base := baseDir(dir)
+	for _, pattern := range patterns {
+		matches, err := filepath.Glob(resolve(base, pattern))
+		if err != nil || len(matches) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func baseDir(dir string) string {
+	if dir == "" {
+		return "."
+	}
+	return dir
+}
+
+func resolve(base string, path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(base, path)
+}
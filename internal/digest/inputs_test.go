@@ -0,0 +1,86 @@
+package digest
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestInputsChangesWithFilesCommandAndEnv(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "src", "pkg"), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	write := func(rel string, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, filepath.FromSlash(rel)), []byte(content), 0o644); err != nil {
+			t.Fatalf("write %s: %v", rel, err)
+		}
+	}
+	write("src/main.go", "package main")
+	write("src/pkg/util.go", "package pkg")
+	write("go.mod", "module example")
+
+	spec := Spec{
+		Dir:      dir,
+		Patterns: []string{"src", "go.*"},
+		Command:  []string{"go", "build"},
+		Env:      map[string]string{"GOOS": "linux"},
+	}
+	digest := func(spec Spec) string {
+		t.Helper()
+		sum, err := Inputs(spec, "sha256")
+		if err != nil {
+			t.Fatalf("Inputs: %v", err)
+		}
+		return sum
+	}
+
+	base := digest(spec)
+	if base != digest(spec) {
+		t.Fatalf("expected a stable digest")
+	}
+
+	files, err := Files(spec.Patterns, dir)
+	if err != nil {
+		t.Fatalf("Files: %v", err)
+	}
+	want := []string{"go.mod", "src/main.go", "src/pkg/util.go"}
+	if len(files) != len(want) {
+		t.Fatalf("expected files %v, got %v", want, files)
+	}
+	for i := range want {
+		if files[i] != want[i] {
+			t.Fatalf("expected files %v, got %v", want, files)
+		}
+	}
+
+	changed := spec
+	changed.Command = []string{"go", "build", "-race"}
+	if digest(changed) == base {
+		t.Fatalf("expected the command to change the digest")
+	}
+	changed = spec
+	changed.Env = map[string]string{"GOOS": "darwin"}
+	if digest(changed) == base {
+		t.Fatalf("expected the env to change the digest")
+	}
+
+	write("src/pkg/util.go", "package pkg // changed")
+	if digest(spec) == base {
+		t.Fatalf("expected a changed input file to change the digest")
+	}
+}
+
+func TestExist(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "out.txt"), nil, 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if !Exist([]string{"out.txt", "*.txt"}, dir) {
+		t.Fatalf("expected outputs to exist")
+	}
+	if Exist([]string{"out.txt", "missing.bin"}, dir) {
+		t.Fatalf("expected a missing output to be reported")
+	}
+}
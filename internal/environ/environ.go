@@ -0,0 +1,228 @@
+package environ
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	InheritAll  = "all"
+	InheritNone = "none"
+)
+
+// Spec describes how a command environment is assembled.
+type Spec struct {
+	// Inherit is InheritAll, InheritNone, or empty when Allow lists the
+	// parent variables to keep.
+	Inherit string
+	Allow   []string
+	// Files are dotenv files applied in order; relative paths resolve
+	// against BaseDir.
+	Files   []string
+	BaseDir string
+	// Env is applied last and may reference other variables as ${NAME};
+	// only inherited and env file variables resolve.
+	Env map[string]string
+}
+
+// ParseInherit normalizes the inherit_env config value, which is either
+// "all", "none", or a list of variable names to keep.
+func ParseInherit(value any) (string, []string, error) {
+	switch v := value.(type) {
+	case nil:
+		return InheritAll, nil, nil
+	case string:
+		switch v {
+		case "", InheritAll:
+			return InheritAll, nil, nil
+		case InheritNone:
+			return InheritNone, nil, nil
+		}
+		return "", nil, fmt.Errorf("inherit_env must be \"all\", \"none\", or a list: %s", v)
+	case []string:
+		return "", v, nil
+	case []any:
+		allow := make([]string, 0, len(v))
+		for _, item := range v {
+			name, ok := item.(string)
+			if !ok {
+				return "", nil, fmt.Errorf("inherit_env list must contain strings: %v", item)
+			}
+			allow = append(allow, name)
+		}
+		return "", allow, nil
+	default:
+		return "", nil, fmt.Errorf("inherit_env must be \"all\", \"none\", or a list: %v", value)
+	}
+}
+
+// Build assembles the environment for a command from the parent environment
+// and the spec: inherited variables, then env files, then explicit values.
+func Build(parent []string, spec Spec) ([]string, error) {
+	parentVars := toMap(parent)
+	vars := make(map[string]string)
+	switch {
+	case spec.Inherit == InheritNone:
+	case spec.Inherit == InheritAll || (spec.Inherit == "" && spec.Allow == nil):
+		for key, value := range parentVars {
+			vars[key] = value
+		}
+	default:
+		for _, key := range spec.Allow {
+			if value, ok := parentVars[key]; ok {
+				vars[key] = value
+			}
+		}
+	}
+
+	// References see only what the command gets, so variables left out by
+	// inherit_env do not leak back in through ${NAME}.
+	lookup := func(name string) string {
+		return vars[name]
+	}
+
+	for _, file := range spec.Files {
+		path := file
+		if !filepath.IsAbs(path) && spec.BaseDir != "" {
+			path = filepath.Join(spec.BaseDir, path)
+		}
+		values, err := ReadFile(path, lookup)
+		if err != nil {
+			return nil, err
+		}
+		for _, kv := range values {
+			vars[kv[0]] = kv[1]
+		}
+	}
+
+	expanded := make(map[string]string, len(spec.Env))
+	for key, value := range spec.Env {
+		expanded[key] = Expand(value, lookup)
+	}
+	for key, value := range expanded {
+		vars[key] = value
+	}
+
+	keys := make([]string, 0, len(vars))
+	for key := range vars {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	env := make([]string, 0, len(keys))
+	for _, key := range keys {
+		env = append(env, key+"="+vars[key])
+	}
+	return env, nil
+}
+
+var varRef = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// Expand replaces ${NAME} references using lookup.
+func Expand(value string, lookup func(string) string) string {
+	return varRef.ReplaceAllStringFunc(value, func(ref string) string {
+		return lookup(ref[2 : len(ref)-1])
+	})
+}
+
+// ReadFile parses a dotenv file into ordered key/value pairs. Values in
+// double quotes or unquoted may reference ${NAME}; single-quoted values are
+// taken literally.
+func ReadFile(path string, lookup func(string) string) ([][2]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open env file: %w", err)
+	}
+	defer file.Close()
+
+	seen := make(map[string]string)
+	values := make([][2]string, 0)
+	resolve := func(name string) string {
+		if value, ok := seen[name]; ok {
+			return value
+		}
+		return lookup(name)
+	}
+
+	scanner := bufio.NewScanner(file)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+
+		key, raw, ok := strings.Cut(line, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("%s:%d: expected KEY=VALUE", path, lineNo)
+		}
+
+		value, err := parseValue(strings.TrimSpace(raw), resolve)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, lineNo, err)
+		}
+		seen[key] = value
+		values = append(values, [2]string{key, value})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read env file: %w", err)
+	}
+	return values, nil
+}
+
+func parseValue(raw string, lookup func(string) string) (string, error) {
+	if raw == "" {
+		return "", nil
+	}
+	switch raw[0] {
+	case '\'':
+		end := strings.IndexByte(raw[1:], '\'')
+		if end < 0 {
+			return "", fmt.Errorf("unterminated single quote")
+		}
+		return raw[1 : end+1], nil
+	case '"':
+		var out strings.Builder
+		for i := 1; i < len(raw); i++ {
+			c := raw[i]
+			if c == '"' {
+				return Expand(out.String(), lookup), nil
+			}
+			if c == '\\' && i+1 < len(raw) {
+				i++
+				switch raw[i] {
+				case 'n':
+					out.WriteByte('\n')
+				case 't':
+					out.WriteByte('\t')
+				default:
+					out.WriteByte(raw[i])
+				}
+				continue
+			}
+			out.WriteByte(c)
+		}
+		return "", fmt.Errorf("unterminated double quote")
+	}
+
+	if idx := strings.Index(raw, " #"); idx >= 0 {
+		raw = strings.TrimSpace(raw[:idx])
+	}
+	return Expand(raw, lookup), nil
+}
+
+func toMap(env []string) map[string]string {
+	vars := make(map[string]string, len(env))
+	for _, entry := range env {
+		key, value, _ := strings.Cut(entry, "=")
+		vars[key] = value
+	}
+	return vars
+}
@@ -0,0 +1,113 @@
+package environ
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBuildAllowlistFilesAndExpansion(t *testing.T) {
+	dir := t.TempDir()
+	envFile := filepath.Join(dir, "app.env")
+	content := strings.Join([]string{
+		"# comment",
+		"export DB_HOST=db.local",
+		`DB_URL="postgres://${DB_HOST}/app"`,
+		"LITERAL='${DB_HOST}'",
+		"PLAIN=value # trailing comment",
+		"",
+	}, "\n")
+	if err := os.WriteFile(envFile, []byte(content), 0o644); err != nil {
+		t.Fatalf("write env file: %v", err)
+	}
+
+	parent := []string{"HOME=/home/user", "PATH=/usr/bin", "SECRET=leak"}
+	env, err := Build(parent, Spec{
+		Allow:   []string{"PATH"},
+		Files:   []string{"app.env"},
+		BaseDir: dir,
+		Env:     map[string]string{"PATH": "/opt/bin:${PATH}", "WHO": "${HOME}"},
+	})
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+
+	got := toMap(env)
+	expected := map[string]string{
+		"PATH":    "/opt/bin:/usr/bin",
+		"WHO":     "",
+		"DB_HOST": "db.local",
+		"DB_URL":  "postgres://db.local/app",
+		"LITERAL": "${DB_HOST}",
+		"PLAIN":   "value",
+	}
+	if len(got) != len(expected) {
+		t.Fatalf("unexpected env: %v", env)
+	}
+	for key, value := range expected {
+		if got[key] != value {
+			t.Fatalf("expected %s=%q, got %q", key, value, got[key])
+		}
+	}
+}
+
+func TestBuildInheritModes(t *testing.T) {
+	parent := []string{"HOME=/home/user"}
+
+	env, err := Build(parent, Spec{Inherit: InheritNone, Env: map[string]string{"A": "1"}})
+	if err != nil {
+		t.Fatalf("Build none: %v", err)
+	}
+	if len(env) != 1 || env[0] != "A=1" {
+		t.Fatalf("unexpected env for none: %v", env)
+	}
+
+	env, err = Build(parent, Spec{Inherit: InheritAll})
+	if err != nil {
+		t.Fatalf("Build all: %v", err)
+	}
+	if len(env) != 1 || env[0] != "HOME=/home/user" {
+		t.Fatalf("unexpected env for all: %v", env)
+	}
+}
+
+func TestBuildInheritNoneDoesNotExpandParent(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "app.env"), []byte("DATA=${HOME}/data\n"), 0o644); err != nil {
+		t.Fatalf("write env file: %v", err)
+	}
+
+	parent := []string{"HOME=/home/user"}
+	env, err := Build(parent, Spec{
+		Inherit: InheritNone,
+		Files:   []string{"app.env"},
+		BaseDir: dir,
+		Env:     map[string]string{"WHO": "${HOME}"},
+	})
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	got := toMap(env)
+	if got["WHO"] != "" || got["DATA"] != "/data" {
+		t.Fatalf("expected ${HOME} to expand to an empty string, got %v", env)
+	}
+}
+
+func TestParseInherit(t *testing.T) {
+	if mode, _, err := ParseInherit(nil); err != nil || mode != InheritAll {
+		t.Fatalf("expected default all, got %q %v", mode, err)
+	}
+	if mode, _, err := ParseInherit("none"); err != nil || mode != InheritNone {
+		t.Fatalf("expected none, got %q %v", mode, err)
+	}
+	if _, allow, err := ParseInherit([]any{"PATH", "HOME"}); err != nil || len(allow) != 2 {
+		t.Fatalf("expected allowlist, got %v %v", allow, err)
+	}
+	if _, _, err := ParseInherit("some"); err == nil {
+		t.Fatalf("expected error for invalid mode")
+	}
+	if _, _, err := ParseInherit([]any{1}); err == nil {
+		t.Fatalf("expected error for non-string list")
+	}
+}
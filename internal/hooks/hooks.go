@@ -0,0 +1,154 @@
+// Package hooks runs the lifecycle hook commands configured around a job
+// or task build.
+package hooks
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"orchastration/internal/config"
+	"orchastration/internal/outcome"
+	"orchastration/internal/runner"
+	"orchastration/internal/state"
+)
+
+const (
+	Before    = "before"
+	OnSuccess = "on_success"
+	OnFailure = "on_failure"
+	Always    = "always"
+)
+
+// Context describes the run hooks are attached to. It reaches hook
+// commands as ORCHASTRATION_* environment variables.
+type Context struct {
+	// Kind is "job" or "task" and names the ORCHASTRATION_JOB or
+	// ORCHASTRATION_TASK variable.
+	Kind       string
+	Name       string
+	RunID      string
+	StdoutPath string
+	StderrPath string
+	// The fields below are only set for hooks that run after the command.
+	Status     string
+	Outcome    string
+	ExitCode   int
+	DurationMs int64
+}
+
+// Runner runs hook commands in the run's working directory, environment,
+// and output streams.
+type Runner struct {
+	Hooks   config.HooksConfig
+	Dir     string
+	Env     []string
+	Signals <-chan os.Signal
+	Grace   time.Duration
+	Stdout  io.Writer
+	Stderr  io.Writer
+}
+
+// Run runs the commands of one stage in order, stopping at the first that
+// fails.
+func (r Runner) Run(stage string, ctx Context) ([]state.HookRun, error) {
+	commands := r.commands(stage)
+	runs := make([]state.HookRun, 0, len(commands))
+	for _, command := range commands {
+		cmd := exec.Command(command[0], command[1:]...)
+		cmd.Dir = r.Dir
+		cmd.Env = append(append([]string{}, r.Env...), ctx.env(stage)...)
+		cmd.Stdout = r.Stdout
+		cmd.Stderr = r.Stderr
+
+		start := time.Now().UTC()
+		result := runner.Run(cmd, runner.Options{
+			Timeout: time.Duration(r.Hooks.TimeoutSeconds) * time.Second,
+			Grace:   r.Grace,
+			Signals: r.Signals,
+		})
+		end := time.Now().UTC()
+		runs = append(runs, state.NewHookRun(stage, strings.Join(command, " "), start, end, exitCode(result.Err), result.Status, result.Err))
+		if result.Err != nil {
+			return runs, fmt.Errorf("%s hook %s: %w", stage, command[0], result.Err)
+		}
+	}
+	return runs, nil
+}
+
+// After runs on_success or on_failure, depending on the run's outcome, and
+// then always. A warning outcome counts as success. always runs even when
+// the first stage fails; the first error is returned.
+func (r Runner) After(ctx Context) ([]state.HookRun, error) {
+	stage := OnSuccess
+	if ctx.Outcome == outcome.Failure {
+		stage = OnFailure
+	}
+	runs, err := r.Run(stage, ctx)
+	always, alwaysErr := r.Run(Always, ctx)
+	if err == nil {
+		err = alwaysErr
+	}
+	return append(runs, always...), err
+}
+
+func (r Runner) commands(stage string) [][]string {
+	switch stage {
+	case Before:
+		return r.Hooks.Before
+	case OnSuccess:
+		return r.Hooks.OnSuccess
+	case OnFailure:
+		return r.Hooks.OnFailure
+	case Always:
+		return r.Hooks.Always
+	}
+	return nil
+}
+
+func (c Context) env(stage string) []string {
+	env := []string{
+		"ORCHASTRATION_HOOK=" + stage,
+		"ORCHASTRATION_" + strings.ToUpper(c.Kind) + "=" + c.Name,
+		"ORCHASTRATION_RUN_ID=" + c.RunID,
+		"ORCHASTRATION_STDOUT_PATH=" + absPath(c.StdoutPath),
+		"ORCHASTRATION_STDERR_PATH=" + absPath(c.StderrPath),
+	}
+	if stage == Before {
+		return env
+	}
+	return append(env,
+		"ORCHASTRATION_STATUS="+c.Status,
+		"ORCHASTRATION_OUTCOME="+c.Outcome,
+		"ORCHASTRATION_EXIT_CODE="+strconv.Itoa(c.ExitCode),
+		"ORCHASTRATION_DURATION_MS="+strconv.FormatInt(c.DurationMs, 10),
+	)
+}
+
+// absPath makes log paths usable from the hook's working directory.
+func absPath(path string) string {
+	if path == "" {
+		return ""
+	}
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return path
+}
+
+func exitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode()
+	}
+	return 1
+}
@@ -0,0 +1,74 @@
+//go:build !windows
+
+package hooks
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"orchastration/internal/config"
+	"orchastration/internal/outcome"
+	"orchastration/internal/runner"
+)
+
+func TestAfterRunsMatchingStageAndAlways(t *testing.T) {
+	var stdout bytes.Buffer
+	r := Runner{
+		Hooks: config.HooksConfig{
+			OnSuccess: [][]string{{"sh", "-c", "echo success"}},
+			OnFailure: [][]string{
+				{"sh", "-c", "echo failure $ORCHASTRATION_JOB $ORCHASTRATION_EXIT_CODE $ORCHASTRATION_DURATION_MS $ORCHASTRATION_STDOUT_PATH"},
+				{"sh", "-c", "exit 3"},
+				{"sh", "-c", "echo not reached"},
+			},
+			Always: [][]string{{"sh", "-c", "echo always $ORCHASTRATION_HOOK $ORCHASTRATION_OUTCOME"}},
+		},
+		Stdout: &stdout,
+		Stderr: &stdout,
+	}
+	ctx := Context{
+		Kind:       "job",
+		Name:       "build",
+		RunID:      "01TEST",
+		StdoutPath: "/tmp/out.log",
+		Status:     runner.StatusFailed,
+		Outcome:    outcome.Failure,
+		ExitCode:   2,
+		DurationMs: 150,
+	}
+
+	runs, err := r.After(ctx)
+	if err == nil || !strings.Contains(err.Error(), "on_failure hook") {
+		t.Fatalf("expected on_failure hook error, got %v", err)
+	}
+	if len(runs) != 3 {
+		t.Fatalf("expected 3 hook runs, got %+v", runs)
+	}
+	if runs[1].Stage != OnFailure || runs[1].ExitCode != 3 || runs[1].Status != runner.StatusFailed {
+		t.Fatalf("unexpected failed hook run: %+v", runs[1])
+	}
+	if runs[2].Stage != Always || runs[2].Status != runner.StatusSuccess {
+		t.Fatalf("expected always to run after the failure: %+v", runs[2])
+	}
+	want := "failure build 2 150 /tmp/out.log\nalways always failure\n"
+	if stdout.String() != want {
+		t.Fatalf("unexpected hook output %q", stdout.String())
+	}
+}
+
+func TestBeforeOmitsResultVariables(t *testing.T) {
+	var stdout bytes.Buffer
+	r := Runner{
+		Hooks:  config.HooksConfig{Before: [][]string{{"sh", "-c", "echo ${ORCHASTRATION_TASK}:${ORCHASTRATION_EXIT_CODE-unset}"}}},
+		Stdout: &stdout,
+		Stderr: &stdout,
+	}
+	runs, err := r.Run(Before, Context{Kind: "task", Name: "docs"})
+	if err != nil || len(runs) != 1 {
+		t.Fatalf("before: %v %+v", err, runs)
+	}
+	if stdout.String() != "docs:unset\n" {
+		t.Fatalf("unexpected before output %q", stdout.String())
+	}
+}
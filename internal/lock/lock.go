@@ -0,0 +1,131 @@
+package lock
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"orchastration/internal/platform"
+)
+
+// Holder identifies the process holding a lock.
+type Holder struct {
+	PID       int    `json:"pid"`
+	Host      string `json:"host"`
+	StartTime string `json:"start_time"`
+}
+
+// Current describes this process as a lock holder.
+func Current(now time.Time) Holder {
+	host, _ := os.Hostname()
+	return Holder{PID: os.Getpid(), Host: host, StartTime: now.UTC().Format(time.RFC3339)}
+}
+
+// Stale reports whether the holder is a process on this host that no
+// longer exists. Holders on other hosts are never considered stale.
+func (h Holder) Stale() bool {
+	host, _ := os.Hostname()
+	return h.Host == host && !platform.ProcessAlive(h.PID)
+}
+
+// LockedError reports a lock held by another live process.
+type LockedError struct {
+	Holder Holder
+}
+
+func (e *LockedError) Error() string {
+	return fmt.Sprintf("locked by pid %d on %s since %s", e.Holder.PID, e.Holder.Host, e.Holder.StartTime)
+}
+
+// Lock is a held lock file.
+type Lock struct {
+	path   string
+	holder Holder
+}
+
+// TryAcquire takes the lock at path without waiting. A lock left behind by
+// a dead process is removed and taken over; a live holder yields a
+// *LockedError.
+func TryAcquire(path string, holder Holder) (*Lock, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("create lock dir: %w", err)
+	}
+
+	for attempt := 0; attempt < 3; attempt++ {
+		err := create(path, holder)
+		if err == nil {
+			return &Lock{path: path, holder: holder}, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, err
+		}
+
+		existing, err := Read(path)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err == nil && !existing.Stale() {
+			return nil, &LockedError{Holder: existing}
+		}
+		// Stale or unreadable: re-check the file still holds what was seen
+		// so a lock taken in the meantime is not removed.
+		if current, readErr := Read(path); readErr == nil && current != existing {
+			continue
+		}
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("remove stale lock: %w", err)
+		}
+	}
+	existing, _ := Read(path)
+	return nil, &LockedError{Holder: existing}
+}
+
+// Release removes the lock file if it is still held by this lock.
+func (l *Lock) Release() error {
+	current, err := Read(l.path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	if current != l.holder {
+		return nil
+	}
+	if err := os.Remove(l.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("release lock: %w", err)
+	}
+	return nil
+}
+
+// Read returns the holder recorded in a lock file.
+func Read(path string) (Holder, error) {
+	var holder Holder
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return holder, err
+	}
+	if err := json.Unmarshal(data, &holder); err != nil {
+		return holder, fmt.Errorf("parse lock: %w", err)
+	}
+	return holder, nil
+}
+
+// create writes the holder to a temporary file and links it into place, so
+// the lock never exists without its contents.
+func create(path string, holder Holder) error {
+	data, err := json.MarshalIndent(holder, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal lock: %w", err)
+	}
+	tmp := path + "." + strconv.Itoa(os.Getpid()) + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("write lock: %w", err)
+	}
+	defer os.Remove(tmp)
+	return os.Link(tmp, path)
+}
@@ -0,0 +1,75 @@
+package lock
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestTryAcquireHeld(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "locks", "job.lock")
+	held, err := TryAcquire(path, Current(time.Now()))
+	if err != nil {
+		t.Fatalf("acquire: %v", err)
+	}
+
+	_, err = TryAcquire(path, Current(time.Now()))
+	var locked *LockedError
+	if !errors.As(err, &locked) {
+		t.Fatalf("expected LockedError, got %v", err)
+	}
+	if locked.Holder.PID != os.Getpid() {
+		t.Fatalf("expected holder pid %d, got %d", os.Getpid(), locked.Holder.PID)
+	}
+
+	if err := held.Release(); err != nil {
+		t.Fatalf("release: %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("expected lock file removed, got %v", err)
+	}
+}
+
+func TestTryAcquireRemovesStaleLock(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "job.lock")
+	host, _ := os.Hostname()
+	data, _ := json.Marshal(Holder{PID: 999999999, Host: host, StartTime: "2020-01-01T00:00:00Z"})
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatalf("write stale lock: %v", err)
+	}
+
+	held, err := TryAcquire(path, Current(time.Now()))
+	if err != nil {
+		t.Fatalf("expected stale lock to be taken over: %v", err)
+	}
+	holder, err := Read(path)
+	if err != nil {
+		t.Fatalf("read lock: %v", err)
+	}
+	if holder.PID != os.Getpid() {
+		t.Fatalf("expected lock held by %d, got %d", os.Getpid(), holder.PID)
+	}
+	_ = held.Release()
+}
+
+func TestReleaseKeepsOtherHolder(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "job.lock")
+	held, err := TryAcquire(path, Current(time.Now()))
+	if err != nil {
+		t.Fatalf("acquire: %v", err)
+	}
+	data, _ := json.Marshal(Holder{PID: 1, Host: "elsewhere"})
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatalf("overwrite lock: %v", err)
+	}
+
+	if err := held.Release(); err != nil {
+		t.Fatalf("release: %v", err)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("expected other holder's lock kept: %v", err)
+	}
+}
@@ -11,9 +11,12 @@ import (
 
 type Logger struct {
 	*slog.Logger
+	redactor *Redactor
 }
 
-func New(levelName string, logPath string) (*Logger, error) {
+// New returns a logger writing JSON to console and appending it to the file
+// at logPath.
+func New(levelName string, logPath string, console io.Writer) (*Logger, error) {
 	level, err := parseLevel(levelName)
 	if err != nil {
 		return nil, err
@@ -29,9 +32,22 @@ func New(levelName string, logPath string) (*Logger, error) {
 		return nil, fmt.Errorf("open log file: %w", err)
 	}
 
-	writer := io.MultiWriter(os.Stdout, file)
+	writer := io.MultiWriter(console, file)
 	handler := slog.NewJSONHandler(writer, &slog.HandlerOptions{Level: level})
-	return &Logger{Logger: slog.New(handler)}, nil
+	redactor := NewRedactor()
+	return &Logger{
+		Logger:   slog.New(&redactHandler{inner: handler, r: redactor}),
+		redactor: redactor,
+	}, nil
+}
+
+// Redactor returns the redactor masking secrets in this logger's output.
+// It is nil for loggers not built with New.
+func (l *Logger) Redactor() *Redactor {
+	if l == nil {
+		return nil
+	}
+	return l.redactor
 }
 
 func parseLevel(level string) (slog.Level, error) {
@@ -48,3 +64,9 @@ func parseLevel(level string) (slog.Level, error) {
 		return slog.LevelInfo, fmt.Errorf("unknown log level: %s", level)
 	}
 }
+
+// With returns a logger that adds the attributes to every record and
+// shares this logger's redactor.
+func (l *Logger) With(args ...any) *Logger {
+	return &Logger{Logger: l.Logger.With(args...), redactor: l.redactor}
+}
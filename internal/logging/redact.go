@@ -0,0 +1,224 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const redactedValue = "***"
+
+// maxPendingBytes bounds how much unterminated output a redacting writer
+// holds back while waiting for a newline.
+const maxPendingBytes = 64 * 1024
+
+// Redactor masks registered secret values. A nil Redactor masks nothing.
+type Redactor struct {
+	mu     sync.RWMutex
+	values []string
+}
+
+// NewRedactor creates an empty redactor.
+func NewRedactor() *Redactor {
+	return &Redactor{}
+}
+
+// Add registers values to mask. Empty values are ignored.
+func (r *Redactor) Add(values ...string) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, value := range values {
+		if value == "" {
+			continue
+		}
+		r.values = append(r.values, value)
+	}
+	// Longest first so a secret containing another is masked whole.
+	sort.Slice(r.values, func(i, j int) bool {
+		return len(r.values[i]) > len(r.values[j])
+	})
+}
+
+// String returns s with every registered value masked.
+func (r *Redactor) String(s string) string {
+	if r == nil {
+		return s
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, value := range r.values {
+		s = strings.ReplaceAll(s, value, redactedValue)
+	}
+	return s
+}
+
+func (r *Redactor) bytes(data []byte) []byte {
+	if r == nil {
+		return data
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, value := range r.values {
+		data = bytes.ReplaceAll(data, []byte(value), []byte(redactedValue))
+	}
+	return data
+}
+
+// secretPrefixLen returns the length of the longest tail of data that is
+// the start of a registered value, which must not be passed on yet.
+func (r *Redactor) secretPrefixLen(data []byte) int {
+	if r == nil {
+		return 0
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	longest := 0
+	for _, value := range r.values {
+		for n := len(value) - 1; n > longest; n-- {
+			if n <= len(data) && bytes.HasSuffix(data, []byte(value[:n])) {
+				longest = n
+				break
+			}
+		}
+	}
+	return longest
+}
+
+// Writer masks secrets in everything written through it. Output is passed
+// on a line at a time so a secret split across writes is still caught;
+// Close flushes any unterminated remainder.
+func (r *Redactor) Writer(w io.Writer) io.WriteCloser {
+	return &redactWriter{r: r, w: w}
+}
+
+// LiveWriter is like Writer, but an unterminated line is also passed on
+// once no output has arrived for idle, so prompts and progress bars show
+// up live. Only a tail that could be the start of a secret is held back.
+func (r *Redactor) LiveWriter(w io.Writer, idle time.Duration) io.WriteCloser {
+	return &redactWriter{r: r, w: w, idle: idle}
+}
+
+type redactWriter struct {
+	mu      sync.Mutex
+	r       *Redactor
+	w       io.Writer
+	pending []byte
+	idle    time.Duration
+	timer   *time.Timer
+}
+
+func (rw *redactWriter) Write(data []byte) (int, error) {
+	rw.mu.Lock()
+	defer rw.mu.Unlock()
+
+	rw.pending = append(rw.pending, data...)
+	cut := bytes.LastIndexByte(rw.pending, '\n') + 1
+	if cut == 0 && len(rw.pending) > maxPendingBytes {
+		cut = len(rw.pending)
+	}
+	if cut > 0 {
+		if _, err := rw.w.Write(rw.r.bytes(rw.pending[:cut])); err != nil {
+			return 0, err
+		}
+		rw.pending = append(rw.pending[:0], rw.pending[cut:]...)
+	}
+	if rw.idle > 0 && len(rw.pending) > 0 {
+		if rw.timer == nil {
+			rw.timer = time.AfterFunc(rw.idle, rw.flushIdle)
+		} else {
+			rw.timer.Reset(rw.idle)
+		}
+	}
+	return len(data), nil
+}
+
+// flushIdle passes on the unterminated line once output has gone quiet.
+// Write errors surface on the next Write or Close instead.
+func (rw *redactWriter) flushIdle() {
+	rw.mu.Lock()
+	defer rw.mu.Unlock()
+
+	cut := len(rw.pending) - rw.r.secretPrefixLen(rw.pending)
+	if cut <= 0 {
+		return
+	}
+	_, _ = rw.w.Write(rw.r.bytes(rw.pending[:cut]))
+	rw.pending = append(rw.pending[:0], rw.pending[cut:]...)
+}
+
+func (rw *redactWriter) Close() error {
+	rw.mu.Lock()
+	defer rw.mu.Unlock()
+	if rw.timer != nil {
+		rw.timer.Stop()
+	}
+	if len(rw.pending) == 0 {
+		return nil
+	}
+	_, err := rw.w.Write(rw.r.bytes(rw.pending))
+	rw.pending = nil
+	return err
+}
+
+// redactHandler masks secrets in log messages and attribute values.
+type redactHandler struct {
+	inner slog.Handler
+	r     *Redactor
+}
+
+func (h *redactHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.inner.Enabled(ctx, level)
+}
+
+func (h *redactHandler) Handle(ctx context.Context, record slog.Record) error {
+	masked := slog.NewRecord(record.Time, record.Level, h.r.String(record.Message), record.PC)
+	record.Attrs(func(attr slog.Attr) bool {
+		masked.AddAttrs(h.redactAttr(attr))
+		return true
+	})
+	return h.inner.Handle(ctx, masked)
+}
+
+func (h *redactHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	masked := make([]slog.Attr, 0, len(attrs))
+	for _, attr := range attrs {
+		masked = append(masked, h.redactAttr(attr))
+	}
+	return &redactHandler{inner: h.inner.WithAttrs(masked), r: h.r}
+}
+
+func (h *redactHandler) WithGroup(name string) slog.Handler {
+	return &redactHandler{inner: h.inner.WithGroup(name), r: h.r}
+}
+
+func (h *redactHandler) redactAttr(attr slog.Attr) slog.Attr {
+	value := attr.Value.Resolve()
+	switch value.Kind() {
+	case slog.KindString:
+		return slog.String(attr.Key, h.r.String(value.String()))
+	case slog.KindGroup:
+		group := value.Group()
+		masked := make([]any, 0, len(group))
+		for _, member := range group {
+			masked = append(masked, h.redactAttr(member))
+		}
+		return slog.Group(attr.Key, masked...)
+	case slog.KindAny:
+		switch v := value.Any().(type) {
+		case error:
+			return slog.String(attr.Key, h.r.String(v.Error()))
+		case fmt.Stringer:
+			return slog.String(attr.Key, h.r.String(v.String()))
+		}
+	}
+	return slog.Attr{Key: attr.Key, Value: value}
+}
@@ -0,0 +1,96 @@
+package logging
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRedactWriterSplitWrites(t *testing.T) {
+	redactor := NewRedactor()
+	redactor.Add("hunter2")
+
+	var buf bytes.Buffer
+	w := redactor.Writer(&buf)
+	for _, chunk := range []string{"token=hun", "ter2\n", "tail hunter2"} {
+		if _, err := w.Write([]byte(chunk)); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	if got := buf.String(); got != "token=***\ntail ***" {
+		t.Fatalf("unexpected output: %q", got)
+	}
+}
+
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(data []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(data)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestRedactLiveWriterFlushesPartialLine(t *testing.T) {
+	redactor := NewRedactor()
+	redactor.Add("hunter2")
+
+	var buf syncBuffer
+	w := redactor.LiveWriter(&buf, 10*time.Millisecond)
+	if _, err := w.Write([]byte("progress 50% token=hun")); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for buf.String() == "" && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if got := buf.String(); got != "progress 50% token=" {
+		t.Fatalf("expected the partial line without the secret prefix, got %q", got)
+	}
+
+	if _, err := w.Write([]byte("ter2 done")); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	if got := buf.String(); got != "progress 50% token=*** done" {
+		t.Fatalf("unexpected output: %q", got)
+	}
+}
+
+func TestRedactHandler(t *testing.T) {
+	redactor := NewRedactor()
+	redactor.Add("hunter2")
+
+	var buf bytes.Buffer
+	logger := slog.New(&redactHandler{inner: slog.NewJSONHandler(&buf, nil), r: redactor})
+	logger.Info("using hunter2", "command", "login hunter2", "error", errors.New("bad hunter2"))
+
+	if strings.Contains(buf.String(), "hunter2") {
+		t.Fatalf("secret leaked: %s", buf.String())
+	}
+}
+
+func TestNilRedactor(t *testing.T) {
+	var redactor *Redactor
+	redactor.Add("x")
+	if got := redactor.String("x"); got != "x" {
+		t.Fatalf("expected unchanged value, got %q", got)
+	}
+}
@@ -0,0 +1,277 @@
+// Package notify delivers webhook notifications about finished runs.
+package notify
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"orchastration/internal/config"
+	"orchastration/internal/logging"
+	"orchastration/internal/retry"
+	"orchastration/internal/secrets"
+	"orchastration/internal/state"
+)
+
+const (
+	EventJob           = "job.completed"
+	EventTask          = "task.completed"
+	EventOrchestration = "orchestration.completed"
+
+	// PayloadVersion changes whenever the payload layout does.
+	PayloadVersion = 1
+
+	EventHeader     = "X-Orchastration-Event"
+	DeliveryHeader  = "X-Orchastration-Delivery"
+	SignatureHeader = "X-Orchastration-Signature"
+
+	defaultTimeout = 10 * time.Second
+)
+
+// Payload is the JSON body posted to webhooks. Record holds the run record
+// as written to the state directory.
+type Payload struct {
+	Version int    `json:"version"`
+	Event   string `json:"event"`
+	Name    string `json:"name"`
+	RunID   string `json:"run_id,omitempty"`
+	Status  string `json:"status"`
+	Outcome string `json:"outcome,omitempty"`
+	SentAt  string `json:"sent_at"`
+	Record  any    `json:"record"`
+}
+
+// pending tracks deliveries still in flight across all notifiers. idle is
+// closed whenever count drops to zero.
+var pending struct {
+	sync.Mutex
+	count int
+	idle  chan struct{}
+}
+
+// Notifier posts payloads to the configured webhooks. A nil Notifier
+// sends nothing.
+type Notifier struct {
+	webhooks []config.WebhookConfig
+	secrets  *secrets.Resolver
+	logger   *logging.Logger
+	client   *http.Client
+	sleep    func(time.Duration)
+}
+
+// New returns a notifier for the configured webhooks, or nil when there
+// are none.
+func New(cfg config.NotificationsConfig, resolver *secrets.Resolver, logger *logging.Logger) *Notifier {
+	if len(cfg.Webhooks) == 0 {
+		return nil
+	}
+	return &Notifier{
+		webhooks: cfg.Webhooks,
+		secrets:  resolver,
+		logger:   logger,
+		client:   &http.Client{},
+		sleep:    time.Sleep,
+	}
+}
+
+// Job notifies about a finished job run.
+func (n *Notifier) Job(record state.Record) {
+	n.Send(Payload{
+		Event:   EventJob,
+		Name:    record.JobName,
+		RunID:   record.RunID,
+		Status:  record.Status,
+		Outcome: record.Outcome,
+		Record:  record,
+	})
+}
+
+// Task notifies about a finished task build.
+func (n *Notifier) Task(record state.TaskRunRecord) {
+	status := record.RunStatus
+	if status == "" {
+		status = record.Status
+	}
+	n.Send(Payload{
+		Event:   EventTask,
+		Name:    record.TaskName,
+		RunID:   record.RunID,
+		Status:  status,
+		Outcome: record.Outcome,
+		Record:  record,
+	})
+}
+
+// Orchestration notifies about a finished orchestration run.
+func (n *Notifier) Orchestration(record state.OrchestrationRunRecord) {
+	n.Send(Payload{
+		Event:  EventOrchestration,
+		Name:   record.Orchestration,
+		RunID:  record.RunID,
+		Status: record.Status,
+		Record: record,
+	})
+}
+
+// Send delivers the payload to every matching webhook in the background,
+// so a slow target never holds up the run. Failures are logged and never
+// returned, so notifications cannot fail a run.
+func (n *Notifier) Send(payload Payload) {
+	if n == nil {
+		return
+	}
+	payload.Version = PayloadVersion
+	payload.SentAt = time.Now().UTC().Format(time.RFC3339)
+	body, err := json.Marshal(payload)
+	if err != nil {
+		n.logger.Error("webhook payload failed", "event", payload.Event, "error", err)
+		return
+	}
+	for _, hook := range n.webhooks {
+		if !matches(hook, payload) {
+			continue
+		}
+		startDelivery()
+		go func(hook config.WebhookConfig) {
+			defer finishDelivery()
+			n.deliver(hook, payload, body)
+		}(hook)
+	}
+}
+
+// Wait blocks until every delivery started by Send has finished or limit
+// has passed, and reports whether they all finished.
+func Wait(limit time.Duration) bool {
+	pending.Lock()
+	if pending.count == 0 {
+		pending.Unlock()
+		return true
+	}
+	idle := pending.idle
+	pending.Unlock()
+
+	timer := time.NewTimer(limit)
+	defer timer.Stop()
+	select {
+	case <-idle:
+		return true
+	case <-timer.C:
+		return false
+	}
+}
+
+func startDelivery() {
+	pending.Lock()
+	defer pending.Unlock()
+	if pending.count == 0 {
+		pending.idle = make(chan struct{})
+	}
+	pending.count++
+}
+
+func finishDelivery() {
+	pending.Lock()
+	defer pending.Unlock()
+	pending.count--
+	if pending.count == 0 {
+		close(pending.idle)
+	}
+}
+
+func (n *Notifier) deliver(hook config.WebhookConfig, payload Payload, body []byte) {
+	target := hook.Name
+	if target == "" {
+		target = hook.URL
+	}
+	policy, err := retry.NewPolicy(hook.Retries, hook.RetryBackoff, nil)
+	if err != nil {
+		n.logger.Error("webhook delivery failed", "target", target, "event", payload.Event, "error", err)
+		return
+	}
+	signature := ""
+	if hook.Secret != "" {
+		key, err := n.secrets.Resolve(hook.Secret)
+		if err != nil {
+			n.logger.Error("webhook delivery failed", "target", target, "event", payload.Event, "error", err)
+			return
+		}
+		signature = Sign([]byte(key), body)
+	}
+
+	for attempt := 1; ; attempt++ {
+		code, err := n.post(hook, payload, body, signature)
+		if err == nil {
+			n.logger.Info("webhook delivered", "target", target, "event", payload.Event, "name", payload.Name, "attempt", attempt, "status_code", code)
+			return
+		}
+		n.logger.Warn("webhook delivery failed", "target", target, "event", payload.Event, "name", payload.Name, "attempt", attempt, "status_code", code, "error", err)
+		if attempt > policy.Retries {
+			return
+		}
+		n.sleep(policy.Backoff(attempt))
+	}
+}
+
+func (n *Notifier) post(hook config.WebhookConfig, payload Payload, body []byte, signature string) (int, error) {
+	timeout := time.Duration(hook.TimeoutSeconds) * time.Second
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+	req, err := http.NewRequest(http.MethodPost, hook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, payload.Event)
+	if payload.RunID != "" {
+		req.Header.Set(DeliveryHeader, payload.RunID)
+	}
+	if signature != "" {
+		req.Header.Set(SignatureHeader, signature)
+	}
+
+	client := *n.client
+	client.Timeout = timeout
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return resp.StatusCode, nil
+}
+
+// Sign returns the signature header value for a body: the hex HMAC-SHA256
+// of the body under key, prefixed with "sha256=".
+func Sign(key []byte, body []byte) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func matches(hook config.WebhookConfig, payload Payload) bool {
+	if len(hook.Events) > 0 && !contains(hook.Events, payload.Event) {
+		return false
+	}
+	if len(hook.Statuses) > 0 && !contains(hook.Statuses, payload.Status) && !contains(hook.Statuses, payload.Outcome) {
+		return false
+	}
+	return true
+}
+
+func contains(values []string, target string) bool {
+	for _, value := range values {
+		if value == target {
+			return true
+		}
+	}
+	return false
+}
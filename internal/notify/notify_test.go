@@ -0,0 +1,149 @@
+package notify
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"orchastration/internal/config"
+	"orchastration/internal/logging"
+	"orchastration/internal/secrets"
+	"orchastration/internal/state"
+)
+
+type stub struct {
+	mu       sync.Mutex
+	failures int
+	requests []*http.Request
+	bodies   [][]byte
+}
+
+func (s *stub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests = append(s.requests, r)
+	s.bodies = append(s.bodies, body)
+	if s.failures > 0 {
+		s.failures--
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func newNotifier(t *testing.T, hooks ...config.WebhookConfig) *Notifier {
+	t.Helper()
+	logger := &logging.Logger{Logger: slog.New(slog.NewTextHandler(io.Discard, nil))}
+	n := New(config.NotificationsConfig{Webhooks: hooks}, secrets.NewResolver(config.SecretsConfig{}), logger)
+	n.sleep = func(time.Duration) {}
+	return n
+}
+
+func TestJobNotificationSignedAndRetried(t *testing.T) {
+	s := &stub{failures: 2}
+	server := httptest.NewServer(s)
+	defer server.Close()
+
+	n := newNotifier(t, config.WebhookConfig{Name: "ops", URL: server.URL, Secret: "k3y", Retries: 2})
+	record := state.Record{RunID: "01RUN", JobName: "nightly", Status: "failed", Outcome: "failure", ExitCode: 3}
+	n.Job(record)
+	if !Wait(5 * time.Second) {
+		t.Fatalf("delivery did not finish")
+	}
+
+	if len(s.requests) != 3 {
+		t.Fatalf("expected 3 attempts, got %d", len(s.requests))
+	}
+	last := s.requests[2]
+	body := s.bodies[2]
+	if got := last.Header.Get(SignatureHeader); got != Sign([]byte("k3y"), body) {
+		t.Fatalf("unexpected signature %q", got)
+	}
+	if last.Header.Get(EventHeader) != EventJob || last.Header.Get(DeliveryHeader) != "01RUN" {
+		t.Fatalf("unexpected headers %v", last.Header)
+	}
+
+	var payload struct {
+		Version int          `json:"version"`
+		Event   string       `json:"event"`
+		Name    string       `json:"name"`
+		Status  string       `json:"status"`
+		Record  state.Record `json:"record"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		t.Fatalf("decode payload: %v", err)
+	}
+	if payload.Version != PayloadVersion || payload.Event != EventJob || payload.Name != "nightly" || payload.Status != "failed" || payload.Record.ExitCode != 3 {
+		t.Fatalf("unexpected payload %+v", payload)
+	}
+}
+
+func TestFiltersByEventAndStatus(t *testing.T) {
+	s := &stub{}
+	server := httptest.NewServer(s)
+	defer server.Close()
+
+	n := newNotifier(t,
+		config.WebhookConfig{URL: server.URL + "/failures", Statuses: []string{"failed", "warning"}},
+		config.WebhookConfig{URL: server.URL + "/orchestrations", Events: []string{EventOrchestration}},
+	)
+	n.Job(state.Record{JobName: "ok", Status: "success", Outcome: "success"})
+	n.Job(state.Record{JobName: "allowed", Status: "failed", Outcome: "warning"})
+	n.Task(state.TaskRunRecord{TaskName: "build", Status: "done", RunStatus: "success"})
+	n.Orchestration(state.OrchestrationRunRecord{Orchestration: "flow", Status: "failed"})
+	if !Wait(5 * time.Second) {
+		t.Fatalf("deliveries did not finish")
+	}
+
+	paths := make([]string, 0, len(s.requests))
+	for _, r := range s.requests {
+		paths = append(paths, r.URL.Path)
+	}
+	sort.Strings(paths)
+	want := []string{"/failures", "/failures", "/orchestrations"}
+	if len(paths) != len(want) {
+		t.Fatalf("expected deliveries %v, got %v", want, paths)
+	}
+	for i := range want {
+		if paths[i] != want[i] {
+			t.Fatalf("expected deliveries %v, got %v", want, paths)
+		}
+	}
+	if s.requests[0].Header.Get(SignatureHeader) != "" {
+		t.Fatalf("expected unsigned delivery without a secret")
+	}
+}
+
+func TestNilNotifierSendsNothing(t *testing.T) {
+	var n *Notifier
+	n.Job(state.Record{JobName: "quiet"})
+	if New(config.NotificationsConfig{}, nil, nil) != nil {
+		t.Fatalf("expected nil notifier without webhooks")
+	}
+}
+
+func TestSendDoesNotWaitForDelivery(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	n := newNotifier(t, config.WebhookConfig{URL: server.URL})
+	n.Job(state.Record{JobName: "slow", Status: "success"})
+	if Wait(50 * time.Millisecond) {
+		t.Fatalf("expected the delivery to still be in flight")
+	}
+	close(release)
+	if !Wait(5 * time.Second) {
+		t.Fatalf("delivery did not finish")
+	}
+}
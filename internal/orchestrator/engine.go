@@ -8,12 +8,15 @@ import (
 	"time"
 
 	"orchastration/internal/agent"
+	"orchastration/internal/runid"
 	"orchastration/internal/state"
 )
 
 // OrchestrationEngine coordinates agent execution for a run.
 type OrchestrationEngine struct {
 	Registry *agent.Registry
+	// OnRecord, if set, receives each run record once it is written.
+	OnRecord func(state.OrchestrationRunRecord)
 	now      func() time.Time
 }
 
@@ -84,6 +87,7 @@ func (e *OrchestrationEngine) Run(orchestration string, steps [][]string, stateD
 
 	end := e.now().UTC()
 	record := state.OrchestrationRunRecord{
+		RunID:         runid.At(start),
 		Orchestration: orchestration,
 		Agents:        agentRuns,
 		StartTime:     start.Format(time.RFC3339),
@@ -93,7 +97,10 @@ func (e *OrchestrationEngine) Run(orchestration string, steps [][]string, stateD
 		Context:       ctx.SnapshotStrings(),
 	}
 
-	writeErr := writeOrchestrationRun(stateDir, orchestration, start, record)
+	writeErr := writeOrchestrationRun(stateDir, orchestration, record)
+	if writeErr == nil && e.OnRecord != nil {
+		e.OnRecord(record)
+	}
 	if execErr != nil && writeErr != nil {
 		return errors.Join(execErr, writeErr)
 	}
@@ -103,9 +110,8 @@ func (e *OrchestrationEngine) Run(orchestration string, steps [][]string, stateD
 	return execErr
 }
 
-func writeOrchestrationRun(stateDir string, orchestration string, start time.Time, record state.OrchestrationRunRecord) error {
-	timestamp := start.Format("20060102T150405Z")
-	runPath := filepath.Join(stateDir, "orchestrations", orchestration, timestamp+".json")
+func writeOrchestrationRun(stateDir string, orchestration string, record state.OrchestrationRunRecord) error {
+	runPath := filepath.Join(stateDir, "orchestrations", orchestration, record.RunID+".json")
 	return state.WriteOrchestrationRun(runPath, record)
 }
 
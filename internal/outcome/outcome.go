@@ -0,0 +1,86 @@
+// Package outcome classifies how a job or task run ended according to its
+// exit-code policy.
+package outcome
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+
+	"orchastration/internal/runner"
+)
+
+const (
+	Success = "success"
+	Warning = "warning"
+	Failure = "failure"
+)
+
+// Policy maps exit codes to outcomes. Exit codes in neither list are
+// failures, except 0 when no success codes are configured.
+type Policy struct {
+	SuccessCodes []int
+	WarningCodes []int
+	AllowFailure bool
+}
+
+// NewPolicy validates exit-code settings and builds a policy from them.
+func NewPolicy(success []int, warning []int, allowFailure bool) (Policy, error) {
+	for _, code := range warning {
+		if contains(success, code) {
+			return Policy{}, fmt.Errorf("exit code %d is in both success_exit_codes and warning_exit_codes", code)
+		}
+	}
+	if len(success) == 0 && !contains(warning, 0) {
+		success = []int{0}
+	}
+	return Policy{SuccessCodes: success, WarningCodes: warning, AllowFailure: allowFailure}, nil
+}
+
+// Apply re-evaluates an attempt that ran to completion: an exit code the
+// policy accepts clears the error and reports success, and a zero exit the
+// policy does not accept becomes a failure. Timeouts, cancellations, limit
+// kills, and start errors are returned unchanged.
+func (p Policy) Apply(status string, exitCode int, err error) (string, error) {
+	switch status {
+	case runner.StatusSuccess:
+		if !p.accepts(exitCode) {
+			return runner.StatusFailed, fmt.Errorf("exit status %d is not a success exit code", exitCode)
+		}
+	case runner.StatusFailed:
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && !errors.Is(err, runner.ErrLimitExceeded) && p.accepts(exitCode) {
+			return runner.StatusSuccess, nil
+		}
+	}
+	return status, err
+}
+
+// Outcome derives the outcome of a run from its final status and exit
+// code, after Apply. allow_failure turns failures into warnings, except
+// for runs that were cancelled.
+func (p Policy) Outcome(status string, exitCode int) string {
+	if status == runner.StatusSuccess {
+		if contains(p.WarningCodes, exitCode) {
+			return Warning
+		}
+		return Success
+	}
+	if p.AllowFailure && status != runner.StatusCancelled {
+		return Warning
+	}
+	return Failure
+}
+
+func (p Policy) accepts(exitCode int) bool {
+	return contains(p.SuccessCodes, exitCode) || contains(p.WarningCodes, exitCode)
+}
+
+func contains(values []int, target int) bool {
+	for _, value := range values {
+		if value == target {
+			return true
+		}
+	}
+	return false
+}
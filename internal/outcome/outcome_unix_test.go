@@ -0,0 +1,80 @@
+//go:build !windows
+
+package outcome
+
+import (
+	"errors"
+	"os/exec"
+	"testing"
+
+	"orchastration/internal/runner"
+)
+
+func exitError(t *testing.T, code string) error {
+	t.Helper()
+	err := exec.Command("sh", "-c", "exit "+code).Run()
+	if err == nil {
+		t.Fatalf("expected exit %s to fail", code)
+	}
+	return err
+}
+
+func TestApplyAndOutcome(t *testing.T) {
+	policy, err := NewPolicy(nil, []int{1}, false)
+	if err != nil {
+		t.Fatalf("NewPolicy: %v", err)
+	}
+
+	status, err := policy.Apply(runner.StatusFailed, 1, exitError(t, "1"))
+	if status != runner.StatusSuccess || err != nil {
+		t.Fatalf("expected exit 1 to be accepted, got %s %v", status, err)
+	}
+	if got := policy.Outcome(status, 1); got != Warning {
+		t.Fatalf("expected warning, got %s", got)
+	}
+	if got := policy.Outcome(runner.StatusSuccess, 0); got != Success {
+		t.Fatalf("expected success, got %s", got)
+	}
+
+	status, err = policy.Apply(runner.StatusFailed, 2, exitError(t, "2"))
+	if status != runner.StatusFailed || err == nil || policy.Outcome(status, 2) != Failure {
+		t.Fatalf("expected exit 2 to fail, got %s %v", status, err)
+	}
+
+	startErr := errors.New("exec: not found")
+	if status, err := policy.Apply(runner.StatusFailed, 1, startErr); status != runner.StatusFailed || err == nil {
+		t.Fatalf("expected start errors to stay failures, got %s %v", status, err)
+	}
+}
+
+func TestSuccessCodesReplaceZero(t *testing.T) {
+	policy, err := NewPolicy([]int{3}, nil, false)
+	if err != nil {
+		t.Fatalf("NewPolicy: %v", err)
+	}
+	if status, err := policy.Apply(runner.StatusSuccess, 0, nil); status != runner.StatusFailed || err == nil {
+		t.Fatalf("expected exit 0 to fail, got %s %v", status, err)
+	}
+	if status, err := policy.Apply(runner.StatusFailed, 3, exitError(t, "3")); status != runner.StatusSuccess || err != nil {
+		t.Fatalf("expected exit 3 to succeed, got %s %v", status, err)
+	}
+	if _, err := NewPolicy([]int{1}, []int{1}, false); err == nil {
+		t.Fatalf("expected error for overlapping codes")
+	}
+}
+
+func TestAllowFailure(t *testing.T) {
+	policy, err := NewPolicy(nil, nil, true)
+	if err != nil {
+		t.Fatalf("NewPolicy: %v", err)
+	}
+	if got := policy.Outcome(runner.StatusFailed, 1); got != Warning {
+		t.Fatalf("expected allowed failure to be a warning, got %s", got)
+	}
+	if got := policy.Outcome(runner.StatusTimedOut, -1); got != Warning {
+		t.Fatalf("expected allowed timeout to be a warning, got %s", got)
+	}
+	if got := policy.Outcome(runner.StatusCancelled, -1); got != Failure {
+		t.Fatalf("expected cancellation to stay a failure, got %s", got)
+	}
+}
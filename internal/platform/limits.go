@@ -0,0 +1,22 @@
+package platform
+
+// Limits caps the resources a command's processes may use. Zero fields are
+// unlimited.
+type Limits struct {
+	MemoryBytes int64
+	CPUSeconds  int64
+	OpenFiles   int64
+	MaxProcs    int64
+}
+
+// Names of limits as reported in run records; they match the config keys.
+const (
+	LimitMemory    = "max_memory_bytes"
+	LimitCPU       = "cpu_seconds"
+	LimitOpenFiles = "open_files"
+	LimitProcs     = "max_procs"
+)
+
+func (l Limits) empty() bool {
+	return l.MemoryBytes <= 0 && l.CPUSeconds <= 0 && l.OpenFiles <= 0 && l.MaxProcs <= 0
+}
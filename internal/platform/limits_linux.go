@@ -0,0 +1,297 @@
+//go:build linux
+
+package platform
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+	"unsafe"
+)
+
+const (
+	cgroupRoot  = "/sys/fs/cgroup"
+	rlimitNproc = 6
+)
+
+// LimitGuard enforces Limits on one command. Memory and process caps use a
+// cgroup v2 leaf when the current cgroup delegates the memory and pids
+// controllers, and fall back to rlimits otherwise.
+type LimitGuard struct {
+	limits Limits
+	cgroup string
+	fd     *os.File
+	// The gate holds the command in a shell shim until rlimits are set, so
+	// nothing it spawns escapes them.
+	gateRead  *os.File
+	gateWrite *os.File
+}
+
+// PrepareLimits configures cmd to start inside a cgroup leaf when one is
+// needed and available, and behind a gate when rlimits must be applied.
+// It must be called before cmd.Start.
+func PrepareLimits(cmd *exec.Cmd, limits Limits) (*LimitGuard, error) {
+	guard := &LimitGuard{limits: limits}
+	if limits.empty() {
+		return guard, nil
+	}
+
+	if limits.MemoryBytes > 0 || limits.MaxProcs > 0 {
+		if dir, err := createCgroupLeaf(limits); err == nil {
+			if fd, err := os.Open(dir); err == nil {
+				guard.cgroup, guard.fd = dir, fd
+				if cmd.SysProcAttr == nil {
+					cmd.SysProcAttr = &syscall.SysProcAttr{}
+				}
+				cmd.SysProcAttr.UseCgroupFD = true
+				cmd.SysProcAttr.CgroupFD = int(fd.Fd())
+			} else {
+				_ = os.Remove(dir)
+			}
+		}
+		// Without a usable cgroup v2 delegation, Started applies rlimits.
+	}
+
+	if guard.needsRlimits() {
+		if err := guard.gate(cmd); err != nil {
+			_ = guard.Close()
+			return nil, err
+		}
+	}
+	return guard, nil
+}
+
+func (g *LimitGuard) needsRlimits() bool {
+	if g.limits.CPUSeconds > 0 || g.limits.OpenFiles > 0 {
+		return true
+	}
+	return g.cgroup == "" && (g.limits.MemoryBytes > 0 || g.limits.MaxProcs > 0)
+}
+
+// gate rewrites cmd to run through sh, which blocks reading a pipe until
+// Started has applied the rlimits and then execs the original command.
+func (g *LimitGuard) gate(cmd *exec.Cmd) error {
+	if cmd.Err != nil {
+		return nil
+	}
+	shell, err := exec.LookPath("sh")
+	if err != nil {
+		return fmt.Errorf("resource limits need sh: %w", err)
+	}
+	read, write, err := os.Pipe()
+	if err != nil {
+		return fmt.Errorf("create limit gate: %w", err)
+	}
+	g.gateRead, g.gateWrite = read, write
+
+	cmd.ExtraFiles = append(cmd.ExtraFiles, read)
+	fd := 2 + len(cmd.ExtraFiles)
+	script := fmt.Sprintf(`read -r gate <&%d || exit 125; exec %d<&-; exec "$@"`, fd, fd)
+	cmd.Args = append([]string{"sh", "-c", script, "sh", cmd.Path}, cmd.Args[1:]...)
+	cmd.Path = shell
+	return nil
+}
+
+// Started applies the rlimits to the started process and releases the
+// gate. Limits are inherited by everything the command spawns.
+func (g *LimitGuard) Started(process *os.Process) error {
+	if g.gateWrite == nil {
+		return nil
+	}
+	_ = g.gateRead.Close()
+	defer func() {
+		_ = g.gateWrite.Close()
+		g.gateRead, g.gateWrite = nil, nil
+	}()
+	if err := g.applyRlimits(process.Pid); err != nil {
+		return err
+	}
+	if _, err := g.gateWrite.Write([]byte("\n")); err != nil {
+		return fmt.Errorf("release limit gate: %w", err)
+	}
+	return nil
+}
+
+func (g *LimitGuard) applyRlimits(pid int) error {
+	if g.limits.CPUSeconds > 0 {
+		// The soft limit delivers SIGXCPU; the hard limit a second later kills.
+		cpu := uint64(g.limits.CPUSeconds)
+		if err := prlimit(pid, syscall.RLIMIT_CPU, cpu, cpu+1); err != nil {
+			return fmt.Errorf("set %s: %w", LimitCPU, err)
+		}
+	}
+	if g.limits.OpenFiles > 0 {
+		files := uint64(g.limits.OpenFiles)
+		if err := prlimit(pid, syscall.RLIMIT_NOFILE, files, files); err != nil {
+			return fmt.Errorf("set %s: %w", LimitOpenFiles, err)
+		}
+	}
+	if g.cgroup != "" {
+		return nil
+	}
+	if g.limits.MemoryBytes > 0 {
+		memory := uint64(g.limits.MemoryBytes)
+		if err := prlimit(pid, syscall.RLIMIT_AS, memory, memory); err != nil {
+			return fmt.Errorf("set %s: %w", LimitMemory, err)
+		}
+	}
+	if g.limits.MaxProcs > 0 {
+		// RLIMIT_NPROC counts every process of the user, not just the job.
+		procs := uint64(g.limits.MaxProcs)
+		if err := prlimit(pid, rlimitNproc, procs, procs); err != nil {
+			return fmt.Errorf("set %s: %w", LimitProcs, err)
+		}
+	}
+	return nil
+}
+
+// Hit reports which limits the command ran into. Limits enforced only by
+// rlimits surface as failures inside the command and cannot be detected,
+// except for the CPU limit which ends the process with a signal.
+func (g *LimitGuard) Hit(state *os.ProcessState) []string {
+	var hits []string
+	if g.cgroup != "" {
+		if g.limits.MemoryBytes > 0 && cgroupEvent(g.cgroup, "memory.events", "oom_kill") > 0 {
+			hits = append(hits, LimitMemory)
+		}
+		if g.limits.MaxProcs > 0 && cgroupEvent(g.cgroup, "pids.events", "max") > 0 {
+			hits = append(hits, LimitProcs)
+		}
+	}
+	if g.limits.CPUSeconds > 0 && state != nil && cpuLimitHit(state, g.limits.CPUSeconds) {
+		hits = append(hits, LimitCPU)
+	}
+	return hits
+}
+
+// Close kills anything left in the cgroup leaf and removes it.
+func (g *LimitGuard) Close() error {
+	if g.gateWrite != nil {
+		_ = g.gateRead.Close()
+		_ = g.gateWrite.Close()
+	}
+	if g.cgroup == "" {
+		return nil
+	}
+	_ = g.fd.Close()
+	_ = os.WriteFile(filepath.Join(g.cgroup, "cgroup.kill"), []byte("1"), 0o644)
+	var err error
+	for i := 0; i < 20; i++ {
+		if err = os.Remove(g.cgroup); err == nil || errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return fmt.Errorf("remove cgroup: %w", err)
+}
+
+// createCgroupLeaf makes a child of the current cgroup with the memory and
+// pids limits applied.
+func createCgroupLeaf(limits Limits) (string, error) {
+	data, err := os.ReadFile("/proc/self/cgroup")
+	if err != nil {
+		return "", err
+	}
+	var current string
+	for _, line := range strings.Split(string(data), "\n") {
+		if rest, ok := strings.CutPrefix(line, "0::"); ok {
+			current = rest
+			break
+		}
+	}
+	if current == "" {
+		return "", errors.New("cgroup v2 not in use")
+	}
+
+	parent := filepath.Join(cgroupRoot, current)
+	controllers, err := os.ReadFile(filepath.Join(parent, "cgroup.subtree_control"))
+	if err != nil {
+		return "", err
+	}
+	enabled := strings.Fields(string(controllers))
+	if limits.MemoryBytes > 0 && !contains(enabled, "memory") {
+		return "", errors.New("memory controller not delegated")
+	}
+	if limits.MaxProcs > 0 && !contains(enabled, "pids") {
+		return "", errors.New("pids controller not delegated")
+	}
+
+	dir, err := os.MkdirTemp(parent, "orchastration-")
+	if err != nil {
+		return "", err
+	}
+	settings := make(map[string]string)
+	if limits.MemoryBytes > 0 {
+		settings["memory.max"] = strconv.FormatInt(limits.MemoryBytes, 10)
+		settings["memory.swap.max"] = "0"
+	}
+	if limits.MaxProcs > 0 {
+		settings["pids.max"] = strconv.FormatInt(limits.MaxProcs, 10)
+	}
+	for name, value := range settings {
+		err := os.WriteFile(filepath.Join(dir, name), []byte(value), 0o644)
+		// Swap accounting may be disabled; memory.max still applies.
+		if err != nil && name != "memory.swap.max" {
+			_ = os.Remove(dir)
+			return "", fmt.Errorf("set %s: %w", name, err)
+		}
+	}
+	return dir, nil
+}
+
+func cgroupEvent(dir string, file string, key string) int64 {
+	data, err := os.ReadFile(filepath.Join(dir, file))
+	if err != nil {
+		return 0
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 2 && fields[0] == key {
+			count, _ := strconv.ParseInt(fields[1], 10, 64)
+			return count
+		}
+	}
+	return 0
+}
+
+func cpuLimitHit(state *os.ProcessState, limit int64) bool {
+	status, ok := state.Sys().(syscall.WaitStatus)
+	if !ok || !status.Signaled() {
+		return false
+	}
+	switch status.Signal() {
+	case syscall.SIGXCPU:
+		return true
+	case syscall.SIGKILL:
+		return state.UserTime()+state.SystemTime() >= time.Duration(limit)*time.Second
+	}
+	return false
+}
+
+func prlimit(pid int, resource int, soft uint64, hard uint64) error {
+	limit := syscall.Rlimit{Cur: soft, Max: hard}
+	_, _, errno := syscall.RawSyscall6(syscall.SYS_PRLIMIT64, uintptr(pid), uintptr(resource),
+		uintptr(unsafe.Pointer(&limit)), 0, 0, 0)
+	if errno != 0 {
+		return errno
+	}
+	return nil
+}
+
+func contains(values []string, want string) bool {
+	for _, value := range values {
+		if value == want {
+			return true
+		}
+	}
+	return false
+}
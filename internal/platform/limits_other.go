@@ -0,0 +1,31 @@
+//go:build !linux
+
+package platform
+
+import (
+	"os"
+	"os/exec"
+)
+
+// LimitGuard is a no-op outside Linux; resource limits are not enforced.
+type LimitGuard struct{}
+
+// PrepareLimits returns a guard that enforces nothing on this platform.
+func PrepareLimits(cmd *exec.Cmd, limits Limits) (*LimitGuard, error) {
+	return &LimitGuard{}, nil
+}
+
+// Started does nothing on this platform.
+func (g *LimitGuard) Started(process *os.Process) error {
+	return nil
+}
+
+// Hit reports no limits on this platform.
+func (g *LimitGuard) Hit(state *os.ProcessState) []string {
+	return nil
+}
+
+// Close does nothing on this platform.
+func (g *LimitGuard) Close() error {
+	return nil
+}
@@ -17,6 +17,10 @@ func DefaultConfigPath(appName string) string {
 	return filepath.Join(dir, appName, "config.toml")
 }
 
+func DefaultSecretsPath(appName string) string {
+	return filepath.Join(filepath.Dir(DefaultConfigPath(appName)), "secrets.json")
+}
+
 func DefaultLogPath(appName string) string {
 	dir, err := os.UserCacheDir()
 	if err != nil {
@@ -0,0 +1,42 @@
+//go:build !windows
+
+package platform
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"syscall"
+)
+
+// SetProcessGroup starts the command in its own process group so that
+// signals and kills reach every process it spawns.
+func SetProcessGroup(cmd *exec.Cmd) {
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.Setpgid = true
+}
+
+// SignalGroup sends a signal to the process group led by the process.
+func SignalGroup(process *os.Process, sig os.Signal) error {
+	unixSig, ok := sig.(syscall.Signal)
+	if !ok {
+		unixSig = syscall.SIGTERM
+	}
+	return syscall.Kill(-process.Pid, unixSig)
+}
+
+// KillGroup forcibly kills the process group led by the process.
+func KillGroup(process *os.Process) error {
+	return syscall.Kill(-process.Pid, syscall.SIGKILL)
+}
+
+// ProcessAlive reports whether a process with the PID exists.
+func ProcessAlive(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+	err := syscall.Kill(pid, 0)
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
@@ -0,0 +1,53 @@
+//go:build windows
+
+package platform
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"strconv"
+	"syscall"
+)
+
+// SetProcessGroup starts the command in its own process group so that
+// console interrupts aimed at the CLI do not reach it directly.
+func SetProcessGroup(cmd *exec.Cmd) {
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.CreationFlags |= syscall.CREATE_NEW_PROCESS_GROUP
+}
+
+// SignalGroup asks the process tree to exit. Windows has no POSIX signals,
+// so the signal itself is ignored.
+func SignalGroup(process *os.Process, _ os.Signal) error {
+	return exec.Command("taskkill", "/T", "/PID", strconv.Itoa(process.Pid)).Run()
+}
+
+// KillGroup forcibly kills the process tree rooted at the process.
+func KillGroup(process *os.Process) error {
+	return exec.Command("taskkill", "/T", "/F", "/PID", strconv.Itoa(process.Pid)).Run()
+}
+
+const (
+	processQueryLimitedInformation = 0x1000
+	stillActive                    = 259
+)
+
+// ProcessAlive reports whether a process with the PID is still running.
+func ProcessAlive(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+	handle, err := syscall.OpenProcess(processQueryLimitedInformation, false, uint32(pid))
+	if err != nil {
+		return errors.Is(err, syscall.ERROR_ACCESS_DENIED)
+	}
+	defer syscall.CloseHandle(handle)
+	var code uint32
+	if err := syscall.GetExitCodeProcess(handle, &code); err != nil {
+		return true
+	}
+	return code == stillActive
+}
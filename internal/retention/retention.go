@@ -0,0 +1,204 @@
+package retention
+
+import (
+	"encoding/json"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"orchastration/internal/config"
+	"orchastration/internal/runid"
+)
+
+// Run groups every file and directory that belongs to one recorded run,
+// such as <id>.json, <id>.stdout.log and <id>.stderr.log.
+type Run struct {
+	ID      string
+	Paths   []string
+	Bytes   int64
+	ModTime time.Time
+	// CachedFrom is the run whose logs and outputs a cached run reuses.
+	CachedFrom string
+}
+
+// Effective overlays the non-zero fields of a per-job or per-orchestration
+// policy onto the global one.
+func Effective(global config.RetentionConfig, override config.RetentionConfig) config.RetentionConfig {
+	policy := global
+	if override.KeepLast != 0 {
+		policy.KeepLast = override.KeepLast
+	}
+	if override.MaxAgeDays != 0 {
+		policy.MaxAgeDays = override.MaxAgeDays
+	}
+	if override.MaxTotalBytes != 0 {
+		policy.MaxTotalBytes = override.MaxTotalBytes
+	}
+	if override.AutoPrune {
+		policy.AutoPrune = true
+	}
+	return policy
+}
+
+// Enabled reports whether the policy limits anything.
+func Enabled(policy config.RetentionConfig) bool {
+	return policy.KeepLast > 0 || policy.MaxAgeDays > 0 || policy.MaxTotalBytes > 0
+}
+
+// ListRuns groups the entries of a run directory by run ID, oldest first.
+// last.json is never part of a group.
+func ListRuns(dir string) ([]Run, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[string]*Run)
+	for _, entry := range entries {
+		name := entry.Name()
+		if name == "last.json" {
+			continue
+		}
+		id, _, _ := strings.Cut(name, ".")
+		if id == "" {
+			continue
+		}
+
+		path := filepath.Join(dir, name)
+		size, modTime, err := diskUsage(path)
+		if err != nil {
+			return nil, err
+		}
+
+		run, ok := byID[id]
+		if !ok {
+			run = &Run{ID: id}
+			byID[id] = run
+		}
+		if name == id+".json" {
+			run.CachedFrom = readCachedFrom(path)
+		}
+		run.Paths = append(run.Paths, path)
+		run.Bytes += size
+		if modTime.After(run.ModTime) {
+			run.ModTime = modTime
+		}
+	}
+
+	runs := make([]Run, 0, len(byID))
+	for _, run := range byID {
+		runs = append(runs, *run)
+	}
+	sort.Slice(runs, func(i, j int) bool {
+		return runid.Less(runs[i].ID, runs[j].ID)
+	})
+	return runs, nil
+}
+
+// readCachedFrom returns the cached_from of a run record, if any.
+func readCachedFrom(path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	var record struct {
+		CachedFrom string `json:"cached_from"`
+	}
+	if json.Unmarshal(data, &record) != nil {
+		return ""
+	}
+	return record.CachedFrom
+}
+
+// Select returns the runs the policy would delete. The newest run is always
+// kept, whatever the policy says, and so is every run a kept cached run
+// reuses.
+func Select(runs []Run, policy config.RetentionConfig, now time.Time) []Run {
+	if len(runs) <= 1 || !Enabled(policy) {
+		return nil
+	}
+
+	maxAge := time.Duration(policy.MaxAgeDays) * 24 * time.Hour
+	var total int64
+	overBudget := false
+	expired := make([]Run, 0)
+	for i := len(runs) - 1; i >= 0; i-- {
+		run := runs[i]
+		rank := len(runs) - i
+		switch {
+		case rank == 1:
+		case policy.KeepLast > 0 && rank > policy.KeepLast:
+			expired = append(expired, run)
+			continue
+		case maxAge > 0 && now.Sub(run.ModTime) > maxAge:
+			expired = append(expired, run)
+			continue
+		case overBudget || policy.MaxTotalBytes > 0 && total+run.Bytes > policy.MaxTotalBytes:
+			// Once the budget is spent, every older run goes too.
+			overBudget = true
+			expired = append(expired, run)
+			continue
+		}
+		total += run.Bytes
+	}
+	return keepCachedSources(runs, expired)
+}
+
+// keepCachedSources drops from expired every run that a run staying behind
+// names in CachedFrom.
+func keepCachedSources(runs []Run, expired []Run) []Run {
+	removed := make(map[string]bool, len(expired))
+	for _, run := range expired {
+		removed[run.ID] = true
+	}
+	needed := make(map[string]bool)
+	for _, run := range runs {
+		if !removed[run.ID] && run.CachedFrom != "" {
+			needed[run.CachedFrom] = true
+		}
+	}
+	kept := expired[:0]
+	for _, run := range expired {
+		if !needed[run.ID] {
+			kept = append(kept, run)
+		}
+	}
+	return kept
+}
+
+// Remove deletes every path of the given runs.
+func Remove(runs []Run) error {
+	for _, run := range runs {
+		for _, path := range run.Paths {
+			if err := os.RemoveAll(path); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func diskUsage(path string) (int64, time.Time, error) {
+	var size int64
+	var modTime time.Time
+	err := filepath.WalkDir(path, func(_ string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		if !entry.IsDir() {
+			size += info.Size()
+		}
+		if info.ModTime().After(modTime) {
+			modTime = info.ModTime()
+		}
+		return nil
+	})
+	return size, modTime, err
+}
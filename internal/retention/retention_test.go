@@ -0,0 +1,116 @@
+package retention
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"orchastration/internal/config"
+	"orchastration/internal/runid"
+)
+
+func TestListRunsGroupsFilesAndSkipsLast(t *testing.T) {
+	dir := t.TempDir()
+	newID := runid.At(time.Date(2026, 3, 3, 0, 0, 0, 0, time.UTC))
+	files := map[string]string{
+		"20260301T000000Z.json":       "{}",
+		"20260301T000000Z.stdout.log": "out",
+		"20260301T000000Z.stderr.log": "",
+		"20260302T000000Z.json":       "{}",
+		"last.json":                   "{}",
+		newID + ".json":               "{}",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+
+	runs, err := ListRuns(dir)
+	if err != nil {
+		t.Fatalf("ListRuns: %v", err)
+	}
+	if len(runs) != 3 {
+		t.Fatalf("expected 3 runs, got %d", len(runs))
+	}
+	if runs[0].ID != "20260301T000000Z" || len(runs[0].Paths) != 3 || runs[0].Bytes != 5 {
+		t.Fatalf("unexpected first run: %+v", runs[0])
+	}
+	if runs[2].ID != newID {
+		t.Fatalf("expected %s to sort after legacy IDs, got %+v", newID, runs)
+	}
+}
+
+func TestSelect(t *testing.T) {
+	now := time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC)
+	runs := []Run{
+		{ID: "a", Bytes: 100, ModTime: now.AddDate(0, 0, -40)},
+		{ID: "b", Bytes: 100, ModTime: now.AddDate(0, 0, -20)},
+		{ID: "c", Bytes: 100, ModTime: now.AddDate(0, 0, -10)},
+		{ID: "d", Bytes: 500, ModTime: now.AddDate(0, 0, -90)},
+	}
+
+	cases := []struct {
+		name     string
+		policy   config.RetentionConfig
+		expected []string
+	}{
+		{"disabled", config.RetentionConfig{}, nil},
+		{"keep last", config.RetentionConfig{KeepLast: 2}, []string{"b", "a"}},
+		{"max age never removes newest", config.RetentionConfig{MaxAgeDays: 15}, []string{"b", "a"}},
+		{"max bytes removes oldest first", config.RetentionConfig{MaxTotalBytes: 650}, []string{"b", "a"}},
+		{"keep last zero still keeps newest", config.RetentionConfig{MaxAgeDays: 1}, []string{"c", "b", "a"}},
+	}
+
+	for _, tc := range cases {
+		expired := Select(runs, tc.policy, now)
+		ids := make([]string, 0, len(expired))
+		for _, run := range expired {
+			ids = append(ids, run.ID)
+		}
+		if len(ids) != len(tc.expected) {
+			t.Fatalf("%s: expected %v, got %v", tc.name, tc.expected, ids)
+		}
+		for i := range ids {
+			if ids[i] != tc.expected[i] {
+				t.Fatalf("%s: expected %v, got %v", tc.name, tc.expected, ids)
+			}
+		}
+	}
+}
+
+func TestSelectKeepsCachedSources(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"20260301T000000Z.json":       `{"status":"success"}`,
+		"20260301T000000Z.stdout.log": "built\n",
+		"20260302T000000Z.json":       `{"status":"success"}`,
+		"20260303T000000Z.json":       `{"status":"cached","cached_from":"20260301T000000Z"}`,
+	}
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0o644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+
+	runs, err := ListRuns(dir)
+	if err != nil {
+		t.Fatalf("ListRuns: %v", err)
+	}
+	if runs[2].CachedFrom != "20260301T000000Z" {
+		t.Fatalf("expected cached_from to be read, got %+v", runs[2])
+	}
+	expired := Select(runs, config.RetentionConfig{KeepLast: 1}, time.Now())
+	if len(expired) != 1 || expired[0].ID != "20260302T000000Z" {
+		t.Fatalf("expected only the unreferenced run to expire, got %+v", expired)
+	}
+}
+
+func TestEffectiveOverridesNonZeroFields(t *testing.T) {
+	global := config.RetentionConfig{KeepLast: 100, MaxAgeDays: 30}
+	policy := Effective(global, config.RetentionConfig{KeepLast: 5, AutoPrune: true})
+	if policy.KeepLast != 5 || policy.MaxAgeDays != 30 || !policy.AutoPrune {
+		t.Fatalf("unexpected policy: %+v", policy)
+	}
+}
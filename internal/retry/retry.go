@@ -0,0 +1,99 @@
+package retry
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"time"
+
+	"orchastration/internal/config"
+)
+
+const (
+	StrategyFixed       = "fixed"
+	StrategyExponential = "exponential"
+
+	defaultDelay = time.Second
+)
+
+// Policy decides whether a failed attempt is retried and how long to wait.
+type Policy struct {
+	Retries   int
+	Strategy  string
+	Delay     time.Duration
+	MaxDelay  time.Duration
+	Jitter    bool
+	ExitCodes []int
+	randFloat func() float64
+}
+
+// NewPolicy validates retry settings and builds a policy from them.
+func NewPolicy(retries int, backoff config.BackoffConfig, exitCodes []int) (Policy, error) {
+	if retries < 0 {
+		return Policy{}, fmt.Errorf("retries must not be negative: %d", retries)
+	}
+
+	strategy := backoff.Strategy
+	switch strategy {
+	case "":
+		strategy = StrategyFixed
+	case StrategyFixed, StrategyExponential:
+	default:
+		return Policy{}, fmt.Errorf("unsupported retry backoff strategy: %s", backoff.Strategy)
+	}
+
+	delay := time.Duration(backoff.DelayMs) * time.Millisecond
+	if delay <= 0 {
+		delay = defaultDelay
+	}
+
+	return Policy{
+		Retries:   retries,
+		Strategy:  strategy,
+		Delay:     delay,
+		MaxDelay:  time.Duration(backoff.MaxDelayMs) * time.Millisecond,
+		Jitter:    backoff.Jitter,
+		ExitCodes: exitCodes,
+		randFloat: rand.Float64,
+	}, nil
+}
+
+// ShouldRetry reports whether another attempt follows a failed one.
+// An empty exit code list retries every non-zero exit.
+func (p Policy) ShouldRetry(attempt int, exitCode int) bool {
+	if exitCode == 0 || attempt > p.Retries {
+		return false
+	}
+	if len(p.ExitCodes) == 0 {
+		return true
+	}
+	for _, code := range p.ExitCodes {
+		if code == exitCode {
+			return true
+		}
+	}
+	return false
+}
+
+// Backoff returns the wait before the attempt following the given one.
+func (p Policy) Backoff(attempt int) time.Duration {
+	delay := p.Delay
+	if p.Strategy == StrategyExponential {
+		// Doubling stops before it overflows, which would turn the delay
+		// negative and retry at once.
+		for i := 1; i < attempt && delay <= math.MaxInt64/2; i++ {
+			delay *= 2
+			if p.MaxDelay > 0 && delay >= p.MaxDelay {
+				break
+			}
+		}
+	}
+	if p.MaxDelay > 0 && delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+	if p.Jitter && p.randFloat != nil {
+		half := delay / 2
+		delay = half + time.Duration(p.randFloat()*float64(delay-half))
+	}
+	return delay
+}
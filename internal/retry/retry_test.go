@@ -0,0 +1,76 @@
+package retry
+
+import (
+	"testing"
+	"time"
+
+	"orchastration/internal/config"
+)
+
+func TestShouldRetry(t *testing.T) {
+	policy, err := NewPolicy(2, config.BackoffConfig{}, []int{75})
+	if err != nil {
+		t.Fatalf("NewPolicy: %v", err)
+	}
+
+	if !policy.ShouldRetry(1, 75) {
+		t.Fatalf("expected retry for listed exit code")
+	}
+	if policy.ShouldRetry(1, 1) {
+		t.Fatalf("expected no retry for unlisted exit code")
+	}
+	if policy.ShouldRetry(3, 75) {
+		t.Fatalf("expected no retry once retries are exhausted")
+	}
+	if policy.ShouldRetry(1, 0) {
+		t.Fatalf("expected no retry after success")
+	}
+}
+
+func TestBackoffExponentialCapped(t *testing.T) {
+	policy, err := NewPolicy(5, config.BackoffConfig{Strategy: "exponential", DelayMs: 100, MaxDelayMs: 500}, nil)
+	if err != nil {
+		t.Fatalf("NewPolicy: %v", err)
+	}
+
+	expected := []time.Duration{100, 200, 400, 500, 500}
+	for i, want := range expected {
+		if got := policy.Backoff(i + 1); got != want*time.Millisecond {
+			t.Fatalf("attempt %d: expected %v, got %v", i+1, want*time.Millisecond, got)
+		}
+	}
+}
+
+func TestBackoffExponentialWithoutMaxDoesNotOverflow(t *testing.T) {
+	policy, err := NewPolicy(100, config.BackoffConfig{Strategy: "exponential", DelayMs: 1000}, nil)
+	if err != nil {
+		t.Fatalf("NewPolicy: %v", err)
+	}
+
+	previous := policy.Backoff(1)
+	for attempt := 2; attempt <= 100; attempt++ {
+		got := policy.Backoff(attempt)
+		if got < previous {
+			t.Fatalf("attempt %d: delay dropped from %v to %v", attempt, previous, got)
+		}
+		previous = got
+	}
+}
+
+func TestBackoffJitterWithinBounds(t *testing.T) {
+	policy, err := NewPolicy(1, config.BackoffConfig{Strategy: "fixed", DelayMs: 1000, Jitter: true}, nil)
+	if err != nil {
+		t.Fatalf("NewPolicy: %v", err)
+	}
+	policy.randFloat = func() float64 { return 0.5 }
+
+	if got := policy.Backoff(1); got != 750*time.Millisecond {
+		t.Fatalf("expected 750ms, got %v", got)
+	}
+}
+
+func TestNewPolicyRejectsUnknownStrategy(t *testing.T) {
+	if _, err := NewPolicy(1, config.BackoffConfig{Strategy: "linear"}, nil); err == nil {
+		t.Fatalf("expected unsupported strategy error")
+	}
+}
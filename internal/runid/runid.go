@@ -0,0 +1,130 @@
+// Package runid generates sortable unique run IDs.
+//
+// IDs follow the ULID layout: a 48-bit millisecond timestamp followed by 80
+// random bits, in Crockford base32. IDs generated by one process within the
+// same millisecond increment the random part, so they sort in creation
+// order. Older state used second-resolution timestamps such as
+// 20060102T150405Z as run IDs; Compare orders both forms by time.
+package runid
+
+import (
+	"crypto/rand"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	encoding = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+	// Length is the number of characters in a generated ID.
+	Length = 26
+
+	legacyLayout = "20060102T150405Z"
+)
+
+var (
+	mu       sync.Mutex
+	lastMs   uint64
+	lastRand [10]byte
+)
+
+// New returns a run ID for a run starting now.
+func New() string {
+	return At(time.Now())
+}
+
+// At returns a run ID for a run starting at t.
+func At(t time.Time) string {
+	ms := uint64(t.UnixMilli())
+
+	mu.Lock()
+	if ms == lastMs {
+		// Same millisecond: stay monotonic by incrementing the previous
+		// random part rather than drawing a new one.
+		increment(&lastRand)
+	} else {
+		lastMs = ms
+		if _, err := rand.Read(lastRand[:]); err != nil {
+			panic("runid: read random: " + err.Error())
+		}
+	}
+	entropy := lastRand
+	mu.Unlock()
+
+	return encode(ms, entropy)
+}
+
+// Time returns the start time encoded in an ID, for both generated and
+// legacy timestamp IDs. A suffix after the first "-", such as a matrix
+// cell, is ignored.
+func Time(id string) (time.Time, bool) {
+	base, _, _ := strings.Cut(id, "-")
+	if len(base) == Length {
+		var ms uint64
+		for i := 0; i < 10; i++ {
+			value := strings.IndexByte(encoding, base[i])
+			if value < 0 {
+				return time.Time{}, false
+			}
+			ms = ms<<5 | uint64(value)
+		}
+		return time.UnixMilli(int64(ms)).UTC(), true
+	}
+	t, err := time.Parse(legacyLayout, base)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return t, true
+}
+
+// Compare orders run IDs by their start time, then lexically. IDs without
+// a recognizable time sort first.
+func Compare(a string, b string) int {
+	ta, oka := Time(a)
+	tb, okb := Time(b)
+	switch {
+	case oka && !okb:
+		return 1
+	case !oka && okb:
+		return -1
+	case oka && okb && !ta.Equal(tb):
+		return ta.Compare(tb)
+	}
+	return strings.Compare(a, b)
+}
+
+// Less reports whether run a started before run b.
+func Less(a string, b string) bool {
+	return Compare(a, b) < 0
+}
+
+func increment(entropy *[10]byte) {
+	for i := len(entropy) - 1; i >= 0; i-- {
+		entropy[i]++
+		if entropy[i] != 0 {
+			return
+		}
+	}
+}
+
+func encode(ms uint64, entropy [10]byte) string {
+	var out [Length]byte
+	for i := 9; i >= 0; i-- {
+		out[i] = encoding[ms&0x1f]
+		ms >>= 5
+	}
+	// 80 random bits become the last 16 characters, 5 bits at a time.
+	var acc uint64
+	bits := 0
+	pos := 10
+	for _, b := range entropy {
+		acc = acc<<8 | uint64(b)
+		bits += 8
+		for bits >= 5 {
+			bits -= 5
+			out[pos] = encoding[(acc>>uint(bits))&0x1f]
+			pos++
+		}
+	}
+	return string(out[:])
+}
@@ -0,0 +1,55 @@
+package runid
+
+import (
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestAtIsMonotonicWithinMillisecond(t *testing.T) {
+	now := time.Date(2026, 3, 1, 2, 0, 0, 0, time.UTC)
+	ids := make([]string, 0, 100)
+	seen := make(map[string]bool)
+	for i := 0; i < 100; i++ {
+		id := At(now)
+		if len(id) != Length {
+			t.Fatalf("unexpected length %d: %s", len(id), id)
+		}
+		if seen[id] {
+			t.Fatalf("duplicate id %s", id)
+		}
+		seen[id] = true
+		ids = append(ids, id)
+	}
+	if !sort.StringsAreSorted(ids) {
+		t.Fatalf("ids not in creation order: %v", ids)
+	}
+	got, ok := Time(ids[0])
+	if !ok || !got.Equal(now) {
+		t.Fatalf("expected time %s, got %s %v", now, got, ok)
+	}
+}
+
+func TestCompareMixesLegacyIDs(t *testing.T) {
+	ids := []string{
+		At(time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)),
+		"20260301T020000Z-go-1-22_os-linux",
+		At(time.Date(2026, 3, 1, 2, 0, 0, 500_000_000, time.UTC)) + "-os-linux",
+		"20260301T020000Z",
+		"20260228T000000Z",
+	}
+	sort.Slice(ids, func(i, j int) bool { return Less(ids[i], ids[j]) })
+
+	want := []string{"20260228T000000Z", "20260301T020000Z", "20260301T020000Z-go-1-22_os-linux"}
+	for i, id := range want {
+		if ids[i] != id {
+			t.Fatalf("position %d: expected %s, got %v", i, id, ids)
+		}
+	}
+	if tm, _ := Time(ids[3]); tm.Day() != 1 {
+		t.Fatalf("expected the March 1 ID fourth, got %v", ids)
+	}
+	if tm, _ := Time(ids[4]); tm.Day() != 2 {
+		t.Fatalf("expected the March 2 ID last, got %v", ids)
+	}
+}
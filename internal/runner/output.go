@@ -0,0 +1,66 @@
+package runner
+
+import (
+	"io"
+	"sync"
+)
+
+// outputLimit caps the combined bytes written to a command's stdout and
+// stderr. Output past the cap is dropped and exceeded is closed.
+type outputLimit struct {
+	mu       sync.Mutex
+	limit    int64
+	written  int64
+	exceeded chan struct{}
+	once     sync.Once
+}
+
+// drop closes exceeded the first time output is dropped.
+func (l *outputLimit) drop() {
+	l.once.Do(func() { close(l.exceeded) })
+}
+
+func newOutputLimit(limit int64) *outputLimit {
+	return &outputLimit{limit: limit, exceeded: make(chan struct{})}
+}
+
+func (l *outputLimit) wrap(w io.Writer) io.Writer {
+	if w == nil {
+		return nil
+	}
+	return &limitedWriter{limit: l, w: w}
+}
+
+type limitedWriter struct {
+	limit *outputLimit
+	w     io.Writer
+}
+
+// Write always reports the full length so the command is not sent SIGPIPE
+// before it is stopped.
+func (lw *limitedWriter) Write(data []byte) (int, error) {
+	l := lw.limit
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	allowed := l.limit - l.written
+	if allowed <= 0 {
+		if len(data) > 0 {
+			l.drop()
+		}
+		return len(data), nil
+	}
+	chunk := data
+	if int64(len(chunk)) > allowed {
+		chunk = chunk[:allowed]
+	}
+	n, err := lw.w.Write(chunk)
+	l.written += int64(n)
+	if int64(len(data)) > allowed {
+		l.drop()
+	}
+	if err != nil {
+		return n, err
+	}
+	return len(data), nil
+}
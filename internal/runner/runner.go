@@ -0,0 +1,192 @@
+package runner
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"orchastration/internal/config"
+	"orchastration/internal/platform"
+	"orchastration/internal/sandbox"
+)
+
+const (
+	StatusSuccess   = "success"
+	StatusFailed    = "failed"
+	StatusCancelled = "cancelled"
+	StatusTimedOut  = "timed_out"
+
+	// LimitOutput is reported when max_output_bytes is exceeded.
+	LimitOutput = "max_output_bytes"
+
+	// DefaultGrace is how long a signalled process group may take to exit
+	// before it is killed.
+	DefaultGrace = 10 * time.Second
+)
+
+var (
+	ErrCancelled = errors.New("cancelled")
+	ErrTimedOut  = errors.New("timed out")
+	// ErrLimitExceeded wraps errors of commands that ran into a resource limit.
+	ErrLimitExceeded = errors.New("resource limit exceeded")
+)
+
+// Options controls how a command is supervised.
+type Options struct {
+	// Timeout kills the process group once exceeded. Zero means no timeout.
+	Timeout time.Duration
+	// Grace is the wait between forwarding a signal and killing the group.
+	Grace time.Duration
+	// Signals delivers interrupts to forward to the process group.
+	Signals <-chan os.Signal
+	// Limits caps the command's resources; output beyond max_output_bytes
+	// is dropped and the process group is killed.
+	Limits config.LimitsConfig
+	// Sandbox runs the command in new namespaces when enabled.
+	Sandbox config.SandboxConfig
+}
+
+// Result describes how a command ended.
+type Result struct {
+	Status string
+	Err    error
+	// LimitsHit names the limits the command ran into.
+	LimitsHit []string
+}
+
+// Run starts the command in its own process group and waits for it,
+// forwarding signals and killing the whole group on timeout or once the
+// grace period after a forwarded signal expires.
+func Run(cmd *exec.Cmd, opts Options) Result {
+	platform.SetProcessGroup(cmd)
+
+	var exceeded <-chan struct{}
+	if opts.Limits.MaxOutputBytes > 0 {
+		output := newOutputLimit(opts.Limits.MaxOutputBytes)
+		cmd.Stdout, cmd.Stderr = output.wrap(cmd.Stdout), output.wrap(cmd.Stderr)
+		exceeded = output.exceeded
+	}
+	var setup *sandbox.Setup
+	if opts.Sandbox.Enabled {
+		prepared, err := sandbox.Prepare(cmd, opts.Sandbox.Writable)
+		if err != nil {
+			return Result{Status: StatusFailed, Err: err}
+		}
+		setup = prepared
+		defer setup.Close()
+	}
+	guard, err := platform.PrepareLimits(cmd, platform.Limits{
+		MemoryBytes: opts.Limits.MaxMemoryBytes,
+		CPUSeconds:  opts.Limits.CPUSeconds,
+		OpenFiles:   opts.Limits.OpenFiles,
+		MaxProcs:    opts.Limits.MaxProcs,
+	})
+	if err != nil {
+		return Result{Status: StatusFailed, Err: err}
+	}
+	defer guard.Close()
+
+	if err := cmd.Start(); err != nil {
+		if setup != nil {
+			err = fmt.Errorf("%w: %w", sandbox.ErrSetup, err)
+		}
+		return Result{Status: StatusFailed, Err: err}
+	}
+	if err := guard.Started(cmd.Process); err != nil {
+		_ = platform.KillGroup(cmd.Process)
+		_ = cmd.Wait()
+		return Result{Status: StatusFailed, Err: fmt.Errorf("apply limits: %w", err)}
+	}
+	if err := setup.Wait(); err != nil {
+		_ = platform.KillGroup(cmd.Process)
+		_ = cmd.Wait()
+		return Result{Status: StatusFailed, Err: err}
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- cmd.Wait()
+	}()
+
+	var timeout <-chan time.Time
+	if opts.Timeout > 0 {
+		timer := time.NewTimer(opts.Timeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+	grace := opts.Grace
+	if grace <= 0 {
+		grace = DefaultGrace
+	}
+
+	var kill <-chan time.Time
+	var stopped error
+	outputExceeded := false
+	for {
+		select {
+		case err := <-done:
+			hits := guard.Hit(cmd.ProcessState)
+			if outputExceeded {
+				hits = append([]string{LimitOutput}, hits...)
+			}
+			switch {
+			case errors.Is(stopped, ErrTimedOut):
+				return Result{Status: StatusTimedOut, Err: joinStop(stopped, err), LimitsHit: hits}
+			case stopped != nil:
+				return Result{Status: StatusCancelled, Err: joinStop(stopped, err), LimitsHit: hits}
+			case len(hits) > 0 && err != nil:
+				limitErr := fmt.Errorf("%w: %s", ErrLimitExceeded, strings.Join(hits, ", "))
+				return Result{Status: StatusFailed, Err: joinStop(limitErr, err), LimitsHit: hits}
+			case err != nil:
+				return Result{Status: StatusFailed, Err: err, LimitsHit: hits}
+			default:
+				return Result{Status: StatusSuccess, LimitsHit: hits}
+			}
+		case <-exceeded:
+			exceeded = nil
+			if stopped == nil {
+				outputExceeded = true
+				_ = platform.KillGroup(cmd.Process)
+			}
+		case sig := <-opts.Signals:
+			if stopped != nil {
+				continue
+			}
+			stopped = fmt.Errorf("%w by %s", ErrCancelled, sig)
+			_ = platform.SignalGroup(cmd.Process, sig)
+			timer := time.NewTimer(grace)
+			defer timer.Stop()
+			kill = timer.C
+		case <-timeout:
+			if stopped == nil {
+				stopped = fmt.Errorf("%w after %s", ErrTimedOut, opts.Timeout)
+			}
+			_ = platform.KillGroup(cmd.Process)
+		case <-kill:
+			_ = platform.KillGroup(cmd.Process)
+		}
+	}
+}
+
+func joinStop(stopped error, err error) error {
+	if err == nil {
+		return stopped
+	}
+	return fmt.Errorf("%w: %w", stopped, err)
+}
+
+// Sleep waits for the delay unless a signal arrives first, in which case it
+// returns an error wrapping ErrCancelled.
+func Sleep(delay time.Duration, signals <-chan os.Signal) error {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case sig := <-signals:
+		return fmt.Errorf("%w by %s", ErrCancelled, sig)
+	}
+}
@@ -0,0 +1,32 @@
+//go:build linux
+
+package runner
+
+import (
+	"os/exec"
+	"testing"
+
+	"orchastration/internal/config"
+	"orchastration/internal/platform"
+)
+
+func TestRunCPULimit(t *testing.T) {
+	cmd := exec.Command("sh", "-c", "while true; do :; done")
+
+	result := Run(cmd, Options{Limits: config.LimitsConfig{CPUSeconds: 1}})
+	if result.Status != StatusFailed {
+		t.Fatalf("expected failed, got %s (%v)", result.Status, result.Err)
+	}
+	if len(result.LimitsHit) != 1 || result.LimitsHit[0] != platform.LimitCPU {
+		t.Fatalf("expected %s hit, got %v", platform.LimitCPU, result.LimitsHit)
+	}
+}
+
+func TestRunOpenFilesLimit(t *testing.T) {
+	cmd := exec.Command("sh", "-c", "test \"$(ulimit -n)\" = 32")
+
+	result := Run(cmd, Options{Limits: config.LimitsConfig{OpenFiles: 32}})
+	if result.Status != StatusSuccess {
+		t.Fatalf("expected open files limit applied, got %s (%v)", result.Status, result.Err)
+	}
+}
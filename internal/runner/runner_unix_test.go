@@ -0,0 +1,116 @@
+//go:build !windows
+
+package runner
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"orchastration/internal/config"
+)
+
+func TestRunTimeoutKillsProcessGroup(t *testing.T) {
+	pidPath := filepath.Join(t.TempDir(), "child.pid")
+	cmd := exec.Command("sh", "-c", "sleep 30 & echo $! > "+pidPath+"; wait")
+
+	result := Run(cmd, Options{Timeout: 300 * time.Millisecond})
+	if result.Status != StatusTimedOut {
+		t.Fatalf("expected timed_out, got %s (%v)", result.Status, result.Err)
+	}
+
+	data, err := os.ReadFile(pidPath)
+	if err != nil {
+		t.Fatalf("read child pid: %v", err)
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil {
+		t.Fatalf("parse child pid: %v", err)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for syscall.Kill(pid, 0) == nil {
+		if time.Now().After(deadline) {
+			t.Fatalf("grandchild %d still running after timeout", pid)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+func TestRunForwardsSignal(t *testing.T) {
+	signals := make(chan os.Signal, 1)
+	cmd := exec.Command("sleep", "30")
+
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		signals <- syscall.SIGTERM
+	}()
+
+	start := time.Now()
+	result := Run(cmd, Options{Grace: 5 * time.Second, Signals: signals})
+	if result.Status != StatusCancelled {
+		t.Fatalf("expected cancelled, got %s (%v)", result.Status, result.Err)
+	}
+	if time.Since(start) > 3*time.Second {
+		t.Fatalf("process did not exit on forwarded signal")
+	}
+}
+
+func TestRunKillsAfterGrace(t *testing.T) {
+	signals := make(chan os.Signal, 1)
+	cmd := exec.Command("sh", "-c", "trap '' TERM; sleep 30")
+
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		signals <- syscall.SIGTERM
+	}()
+
+	result := Run(cmd, Options{Grace: 200 * time.Millisecond, Signals: signals})
+	if result.Status != StatusCancelled {
+		t.Fatalf("expected cancelled, got %s (%v)", result.Status, result.Err)
+	}
+}
+
+func TestRunMaxOutputBytes(t *testing.T) {
+	var out strings.Builder
+	cmd := exec.Command("sh", "-c", "while true; do echo 0123456789; done")
+	cmd.Stdout = &out
+
+	result := Run(cmd, Options{Limits: config.LimitsConfig{MaxOutputBytes: 64}})
+	if result.Status != StatusFailed || !errors.Is(result.Err, ErrLimitExceeded) {
+		t.Fatalf("expected output limit failure, got %s (%v)", result.Status, result.Err)
+	}
+	if len(result.LimitsHit) != 1 || result.LimitsHit[0] != LimitOutput {
+		t.Fatalf("expected %s hit, got %v", LimitOutput, result.LimitsHit)
+	}
+	if out.Len() != 64 {
+		t.Fatalf("expected 64 bytes captured, got %d", out.Len())
+	}
+}
+
+func TestRunMaxOutputBytesExactFill(t *testing.T) {
+	var out strings.Builder
+	cmd := exec.Command("sh", "-c", "printf aaaa; sleep 0.2; printf bbbb; sleep 3")
+	cmd.Stdout = &out
+
+	start := time.Now()
+	result := Run(cmd, Options{Limits: config.LimitsConfig{MaxOutputBytes: 4}})
+	if result.Status != StatusFailed || !errors.Is(result.Err, ErrLimitExceeded) {
+		t.Fatalf("expected output limit failure, got %s (%v)", result.Status, result.Err)
+	}
+	if len(result.LimitsHit) != 1 || result.LimitsHit[0] != LimitOutput {
+		t.Fatalf("expected %s hit, got %v", LimitOutput, result.LimitsHit)
+	}
+	if out.String() != "aaaa" {
+		t.Fatalf("expected only the first write captured, got %q", out.String())
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Fatalf("expected the command to be stopped, ran for %v", elapsed)
+	}
+}
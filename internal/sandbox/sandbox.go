@@ -0,0 +1,54 @@
+// Package sandbox runs commands in new Linux user, mount, PID, and network
+// namespaces where only chosen paths are writable.
+package sandbox
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// InitArg is the first argument of the re-executed binary that sets up the
+// namespaces and then starts the command.
+const InitArg = "__sandbox-init"
+
+// exitSetupFailed is the init's exit code when the sandbox could not be set
+// up; the reason is reported on the setup pipe.
+const exitSetupFailed = 125
+
+// ErrSetup wraps every failure to set up the sandbox.
+var ErrSetup = errors.New("sandbox setup failed")
+
+// Setup reports how the sandbox init of one command went.
+type Setup struct {
+	read  *os.File
+	write *os.File
+}
+
+// Wait returns the setup error reported by the sandbox init, if any. It
+// must be called after the command has started and blocks until the init
+// has started the command or given up.
+func (s *Setup) Wait() error {
+	if s == nil || s.read == nil {
+		return nil
+	}
+	_ = s.write.Close()
+	data, _ := io.ReadAll(s.read)
+	_ = s.read.Close()
+	s.read, s.write = nil, nil
+	if len(data) > 0 {
+		return fmt.Errorf("%w: %s", ErrSetup, data)
+	}
+	return nil
+}
+
+// Close releases the setup pipe.
+func (s *Setup) Close() {
+	if s == nil || s.read == nil {
+		return
+	}
+	_ = s.read.Close()
+	_ = s.write.Close()
+	s.read, s.write = nil, nil
+}
@@ -0,0 +1,289 @@
+//go:build linux
+
+package sandbox
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"unsafe"
+)
+
+// initSpec is passed from Prepare to Init as the argument after InitArg.
+type initSpec struct {
+	ReportFD int      `json:"report_fd"`
+	Writable []string `json:"writable"`
+}
+
+// Prepare rewrites cmd to start this binary as the sandbox init in new
+// user, mount, PID, and network namespaces; the init then runs the
+// original command with cmd.Dir and writable as the only writable paths.
+// Relative writable paths are taken from cmd.Dir. It must be called before
+// cmd.Start, and the returned Setup waited on after it.
+func Prepare(cmd *exec.Cmd, writable []string) (*Setup, error) {
+	if cmd.Err != nil {
+		return &Setup{}, nil
+	}
+	self, err := os.Executable()
+	if err != nil {
+		return nil, fmt.Errorf("%w: locate executable: %w", ErrSetup, err)
+	}
+
+	base := cmd.Dir
+	if base == "" {
+		if base, err = os.Getwd(); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrSetup, err)
+		}
+	}
+	base, err = filepath.Abs(base)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrSetup, err)
+	}
+	spec := initSpec{Writable: []string{base}}
+	for _, path := range writable {
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(base, path)
+		}
+		spec.Writable = append(spec.Writable, filepath.Clean(path))
+	}
+
+	read, write, err := os.Pipe()
+	if err != nil {
+		return nil, fmt.Errorf("%w: create setup pipe: %w", ErrSetup, err)
+	}
+	cmd.ExtraFiles = append(cmd.ExtraFiles, write)
+	spec.ReportFD = 2 + len(cmd.ExtraFiles)
+	data, err := json.Marshal(spec)
+	if err != nil {
+		_ = read.Close()
+		_ = write.Close()
+		return nil, fmt.Errorf("%w: %w", ErrSetup, err)
+	}
+	cmd.Args = append([]string{self, InitArg, string(data), cmd.Path}, cmd.Args...)
+	cmd.Path = self
+
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	// The init runs as root inside the user namespace so it keeps the
+	// capabilities it needs to mount; that root is the caller outside.
+	cmd.SysProcAttr.Cloneflags |= syscall.CLONE_NEWUSER | syscall.CLONE_NEWNS | syscall.CLONE_NEWPID | syscall.CLONE_NEWNET
+	cmd.SysProcAttr.UidMappings = []syscall.SysProcIDMap{{ContainerID: 0, HostID: os.Getuid(), Size: 1}}
+	cmd.SysProcAttr.GidMappings = []syscall.SysProcIDMap{{ContainerID: 0, HostID: os.Getgid(), Size: 1}}
+	cmd.SysProcAttr.GidMappingsEnableSetgroups = false
+	return &Setup{read: read, write: write}, nil
+}
+
+// Init is the entry point of the sandbox init. It makes every mount
+// read-only except the writable paths, mounts a fresh /proc, brings up
+// loopback, then runs the command and exits with its status. Setup
+// failures are written to the setup pipe.
+func Init(args []string) int {
+	var spec initSpec
+	if len(args) < 3 {
+		fmt.Fprintln(os.Stderr, "sandbox: missing command")
+		return exitSetupFailed
+	}
+	if err := json.Unmarshal([]byte(args[0]), &spec); err != nil {
+		fmt.Fprintf(os.Stderr, "sandbox: parse spec: %v\n", err)
+		return exitSetupFailed
+	}
+	syscall.CloseOnExec(spec.ReportFD)
+	report := os.NewFile(uintptr(spec.ReportFD), "sandbox-setup")
+	fail := func(err error) int {
+		fmt.Fprint(report, err)
+		_ = report.Close()
+		return exitSetupFailed
+	}
+
+	if err := setupMounts(spec.Writable); err != nil {
+		return fail(err)
+	}
+	if err := loopbackUp(); err != nil {
+		return fail(fmt.Errorf("bring up loopback: %w", err))
+	}
+
+	// Signals forwarded to the process group reach the command directly;
+	// the init only has to survive them until the command exits.
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP, syscall.SIGQUIT)
+	go func() {
+		for range signals {
+		}
+	}()
+
+	child := exec.Command(args[1])
+	child.Args = args[2:]
+	child.Stdin, child.Stdout, child.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := child.Start(); err != nil {
+		return fail(fmt.Errorf("start command: %w", err))
+	}
+	_ = report.Close()
+
+	err := child.Wait()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		if status, ok := exitErr.Sys().(syscall.WaitStatus); ok && status.Signaled() {
+			return 128 + int(status.Signal())
+		}
+		return exitErr.ExitCode()
+	}
+	if err != nil {
+		return 1
+	}
+	return 0
+}
+
+// setupMounts binds the writable paths onto themselves, remounts every
+// other mount read-only, and mounts /proc for the new PID namespace.
+func setupMounts(writable []string) error {
+	// The working directory still refers to the mount it was entered on, so
+	// it is entered again once the writable binds are in place.
+	dir, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("working directory: %w", err)
+	}
+	if err := syscall.Mount("", "/", "", syscall.MS_REC|syscall.MS_PRIVATE, ""); err != nil {
+		return fmt.Errorf("make mounts private: %w", err)
+	}
+	for _, path := range writable {
+		if _, err := os.Stat(path); err != nil {
+			return fmt.Errorf("writable path: %w", err)
+		}
+		if err := syscall.Mount(path, path, "", syscall.MS_BIND|syscall.MS_REC, ""); err != nil {
+			return fmt.Errorf("bind writable path %s: %w", path, err)
+		}
+	}
+
+	mounts, err := readMounts()
+	if err != nil {
+		return err
+	}
+	for _, mount := range mounts {
+		if mount.readOnly || within(mount.point, writable) {
+			continue
+		}
+		flags := uintptr(syscall.MS_BIND|syscall.MS_REMOUNT|syscall.MS_RDONLY) | mount.flags
+		if err := syscall.Mount("", mount.point, "", flags, ""); err != nil {
+			if errors.Is(err, syscall.ENOENT) {
+				continue
+			}
+			return fmt.Errorf("remount %s read-only: %w", mount.point, err)
+		}
+	}
+
+	if err := syscall.Mount("proc", "/proc", "proc", syscall.MS_NOSUID|syscall.MS_NODEV|syscall.MS_NOEXEC, ""); err != nil {
+		return fmt.Errorf("mount /proc: %w", err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		return fmt.Errorf("working directory: %w", err)
+	}
+	return nil
+}
+
+type mountEntry struct {
+	point    string
+	readOnly bool
+	// flags are the per-mount flags a remount has to keep, since the
+	// kernel locks them for mounts inherited into a user namespace.
+	flags uintptr
+}
+
+func readMounts() ([]mountEntry, error) {
+	file, err := os.Open("/proc/self/mountinfo")
+	if err != nil {
+		return nil, fmt.Errorf("read mounts: %w", err)
+	}
+	defer file.Close()
+
+	var mounts []mountEntry
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 6 {
+			continue
+		}
+		entry := mountEntry{point: unescapeMount(fields[4])}
+		for _, option := range strings.Split(fields[5], ",") {
+			switch option {
+			case "ro":
+				entry.readOnly = true
+			case "nosuid":
+				entry.flags |= syscall.MS_NOSUID
+			case "nodev":
+				entry.flags |= syscall.MS_NODEV
+			case "noexec":
+				entry.flags |= syscall.MS_NOEXEC
+			case "noatime":
+				entry.flags |= syscall.MS_NOATIME
+			case "nodiratime":
+				entry.flags |= syscall.MS_NODIRATIME
+			case "relatime":
+				entry.flags |= syscall.MS_RELATIME
+			case "strictatime":
+				entry.flags |= syscall.MS_STRICTATIME
+			}
+		}
+		mounts = append(mounts, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read mounts: %w", err)
+	}
+	return mounts, nil
+}
+
+// unescapeMount decodes the octal escapes mountinfo uses for spaces, tabs,
+// newlines, and backslashes.
+func unescapeMount(value string) string {
+	if !strings.Contains(value, `\`) {
+		return value
+	}
+	var out strings.Builder
+	for i := 0; i < len(value); i++ {
+		if value[i] == '\\' && i+3 < len(value) {
+			if code, err := strconv.ParseUint(value[i+1:i+4], 8, 8); err == nil {
+				out.WriteByte(byte(code))
+				i += 3
+				continue
+			}
+		}
+		out.WriteByte(value[i])
+	}
+	return out.String()
+}
+
+func within(path string, roots []string) bool {
+	for _, root := range roots {
+		if path == root || strings.HasPrefix(path, strings.TrimSuffix(root, "/")+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// loopbackUp brings up lo, the only interface in the new network
+// namespace.
+func loopbackUp() error {
+	fd, err := syscall.Socket(syscall.AF_INET, syscall.SOCK_DGRAM|syscall.SOCK_CLOEXEC, 0)
+	if err != nil {
+		return err
+	}
+	defer syscall.Close(fd)
+
+	var ifreq [40]byte
+	copy(ifreq[:syscall.IFNAMSIZ], "lo")
+	*(*uint16)(unsafe.Pointer(&ifreq[syscall.IFNAMSIZ])) = syscall.IFF_UP | syscall.IFF_LOOPBACK | syscall.IFF_RUNNING
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, uintptr(fd), syscall.SIOCSIFFLAGS, uintptr(unsafe.Pointer(&ifreq[0])))
+	if errno != 0 {
+		return errno
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+//go:build linux
+
+package sandbox_test
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"orchastration/internal/config"
+	"orchastration/internal/runner"
+	"orchastration/internal/sandbox"
+)
+
+// The test binary doubles as the sandbox init, as the CLI does.
+func TestMain(m *testing.M) {
+	if len(os.Args) > 1 && os.Args[1] == sandbox.InitArg {
+		os.Exit(sandbox.Init(os.Args[2:]))
+	}
+	os.Exit(m.Run())
+}
+
+func runSandboxed(t *testing.T, dir string, writable []string, script string) runner.Result {
+	t.Helper()
+	cmd := exec.Command("sh", "-c", script)
+	cmd.Dir = dir
+	return runner.Run(cmd, runner.Options{Sandbox: config.SandboxConfig{Enabled: true, Writable: writable}})
+}
+
+func TestSandboxWritablePaths(t *testing.T) {
+	work := t.TempDir()
+	extra := t.TempDir()
+	outside := t.TempDir()
+	if probe := runSandboxed(t, work, nil, "true"); probe.Err != nil {
+		t.Skipf("namespaces unavailable: %v", probe.Err)
+	}
+
+	script := "touch made && touch " + filepath.Join(extra, "made") + " && ! touch " + filepath.Join(outside, "made") + " 2>/dev/null && test \"$(ls /proc | grep -c '^[0-9]')\" -lt 10"
+	result := runSandboxed(t, work, []string{extra}, script)
+	if result.Status != runner.StatusSuccess {
+		t.Fatalf("expected success, got %s (%v)", result.Status, result.Err)
+	}
+	for _, path := range []string{filepath.Join(work, "made"), filepath.Join(extra, "made")} {
+		if _, err := os.Stat(path); err != nil {
+			t.Fatalf("expected %s to be written: %v", path, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(outside, "made")); err == nil {
+		t.Fatalf("expected writes outside the sandbox to fail")
+	}
+}
+
+func TestSandboxSetupFailure(t *testing.T) {
+	work := t.TempDir()
+	if probe := runSandboxed(t, work, nil, "true"); probe.Err != nil {
+		t.Skipf("namespaces unavailable: %v", probe.Err)
+	}
+
+	result := runSandboxed(t, work, []string{filepath.Join(work, "missing")}, "true")
+	if result.Status != runner.StatusFailed || !errors.Is(result.Err, sandbox.ErrSetup) {
+		t.Fatalf("expected a setup failure, got %s (%v)", result.Status, result.Err)
+	}
+}
@@ -0,0 +1,20 @@
+//go:build !linux
+
+package sandbox
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+)
+
+// Prepare fails outside Linux, which has no namespaces to sandbox with.
+func Prepare(cmd *exec.Cmd, writable []string) (*Setup, error) {
+	return nil, fmt.Errorf("%w: namespaces are only available on Linux", ErrSetup)
+}
+
+// Init is never reached outside Linux.
+func Init(args []string) int {
+	fmt.Fprintln(os.Stderr, "sandbox: namespaces are only available on Linux")
+	return exitSetupFailed
+}
@@ -0,0 +1,73 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNextEveryFifteenMinutes(t *testing.T) {
+	sched, err := Parse("*/15 * * * *", "UTC")
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	from := time.Date(2026, 3, 10, 12, 7, 30, 0, time.UTC)
+	next := sched.Next(from)
+	expected := time.Date(2026, 3, 10, 12, 15, 0, 0, time.UTC)
+	if !next.Equal(expected) {
+		t.Fatalf("expected %v, got %v", expected, next)
+	}
+}
+
+func TestNextWeekdayInTimezone(t *testing.T) {
+	sched, err := Parse("30 2 * * mon-fri", "America/New_York")
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	// Saturday 2026-03-14 in New York; the next weekday is Monday 2026-03-16.
+	from := time.Date(2026, 3, 14, 12, 0, 0, 0, time.UTC)
+	next := sched.Next(from)
+	if next.Weekday() != time.Monday || next.Hour() != 2 || next.Minute() != 30 {
+		t.Fatalf("unexpected next fire: %v", next)
+	}
+	if next.Location().String() != "America/New_York" {
+		t.Fatalf("unexpected location: %v", next.Location())
+	}
+}
+
+func TestNextDayOfMonthOrWeekday(t *testing.T) {
+	sched, err := Parse("0 0 1 * 0", "UTC")
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	// 2026-03-02 is a Monday; Sunday 2026-03-08 comes before April 1.
+	next := sched.Next(time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC))
+	expected := time.Date(2026, 3, 8, 0, 0, 0, 0, time.UTC)
+	if !next.Equal(expected) {
+		t.Fatalf("expected %v, got %v", expected, next)
+	}
+}
+
+func TestParseMacro(t *testing.T) {
+	sched, err := Parse("@daily", "UTC")
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	next := sched.Next(time.Date(2026, 12, 31, 23, 59, 0, 0, time.UTC))
+	expected := time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)
+	if !next.Equal(expected) {
+		t.Fatalf("expected %v, got %v", expected, next)
+	}
+}
+
+func TestParseRejectsInvalid(t *testing.T) {
+	invalid := []string{"* * * *", "60 * * * *", "* * * 13 *", "*/0 * * * *", "5-1 * * * *"}
+	for _, expr := range invalid {
+		if _, err := Parse(expr, "UTC"); err == nil {
+			t.Fatalf("expected error for %q", expr)
+		}
+	}
+}
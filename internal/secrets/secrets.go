@@ -0,0 +1,145 @@
+package secrets
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"orchastration/internal/config"
+)
+
+const (
+	envPrefix    = "env:"
+	filePrefix   = "file://"
+	secretPrefix = "secret:"
+
+	// DefaultKeyEnv holds the secrets file key when key_env is not set.
+	DefaultKeyEnv = "ORCHASTRATION_SECRETS_KEY"
+)
+
+// IsRef reports whether a value is a secret reference rather than a literal.
+func IsRef(value string) bool {
+	return strings.HasPrefix(value, envPrefix) ||
+		strings.HasPrefix(value, filePrefix) ||
+		strings.HasPrefix(value, secretPrefix)
+}
+
+// Resolver looks up secret references. The encrypted secrets file is only
+// read the first time a secret: reference is resolved.
+type Resolver struct {
+	cfg    config.SecretsConfig
+	mu     sync.Mutex
+	stored map[string]string
+}
+
+// NewResolver creates a resolver for the configured secrets file.
+func NewResolver(cfg config.SecretsConfig) *Resolver {
+	return &Resolver{cfg: cfg}
+}
+
+// Resolve returns the value a reference points to. Values that are not
+// references are returned unchanged.
+func (r *Resolver) Resolve(ref string) (string, error) {
+	switch {
+	case strings.HasPrefix(ref, envPrefix):
+		name := strings.TrimPrefix(ref, envPrefix)
+		value, ok := os.LookupEnv(name)
+		if !ok {
+			return "", fmt.Errorf("secret %s: environment variable not set", ref)
+		}
+		return value, nil
+	case strings.HasPrefix(ref, filePrefix):
+		path, err := filePath(ref)
+		if err != nil {
+			return "", err
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("secret %s: %w", ref, err)
+		}
+		return strings.TrimRight(string(data), "\r\n"), nil
+	case strings.HasPrefix(ref, secretPrefix):
+		name := strings.TrimPrefix(ref, secretPrefix)
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		if r.stored == nil {
+			stored, err := r.load()
+			if err != nil {
+				return "", fmt.Errorf("secret %s: %w", ref, err)
+			}
+			r.stored = stored
+		}
+		value, ok := r.stored[name]
+		if !ok {
+			return "", fmt.Errorf("secret %s: not found in secrets file", ref)
+		}
+		return value, nil
+	}
+	return ref, nil
+}
+
+// ResolveEnv resolves every reference in an env map. It returns the
+// resolved map and the secret values it produced, for redaction.
+func (r *Resolver) ResolveEnv(env map[string]string) (map[string]string, []string, error) {
+	if len(env) == 0 {
+		return env, nil, nil
+	}
+	keys := make([]string, 0, len(env))
+	for key := range env {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	resolved := make(map[string]string, len(env))
+	values := make([]string, 0)
+	for _, key := range keys {
+		value := env[key]
+		if !IsRef(value) {
+			resolved[key] = value
+			continue
+		}
+		secret, err := r.Resolve(value)
+		if err != nil {
+			return nil, nil, fmt.Errorf("env %s: %w", key, err)
+		}
+		resolved[key] = secret
+		values = append(values, secret)
+	}
+	return resolved, values, nil
+}
+
+func (r *Resolver) load() (map[string]string, error) {
+	if r.cfg.File == "" {
+		return nil, errors.New("no secrets file configured")
+	}
+	key, err := LoadKey(r.cfg)
+	if err != nil {
+		return nil, err
+	}
+	return ReadFile(r.cfg.File, key)
+}
+
+// filePath converts a file:// URL into a local path.
+func filePath(ref string) (string, error) {
+	parsed, err := url.Parse(ref)
+	if err != nil {
+		return "", fmt.Errorf("secret %s: %w", ref, err)
+	}
+	if parsed.Host != "" && parsed.Host != "localhost" {
+		return "", fmt.Errorf("secret %s: file references must be local", ref)
+	}
+	if parsed.Path == "" {
+		return "", fmt.Errorf("secret %s: missing path", ref)
+	}
+	path := filepath.FromSlash(parsed.Path)
+	// file:///C:/dir on Windows parses with a leading slash before the drive.
+	if len(path) > 2 && os.PathSeparator == '\\' && path[0] == '\\' && path[2] == ':' {
+		path = path[1:]
+	}
+	return path, nil
+}
@@ -0,0 +1,74 @@
+package secrets
+
+import (
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"orchastration/internal/config"
+)
+
+func TestResolveEnvAndFile(t *testing.T) {
+	t.Setenv("ORCH_TEST_TOKEN", "env-secret")
+	dir := t.TempDir()
+	path := filepath.Join(dir, "token")
+	if err := os.WriteFile(path, []byte("file-secret\n"), 0o600); err != nil {
+		t.Fatalf("write token: %v", err)
+	}
+	fileRef := (&url.URL{Scheme: "file", Path: filepath.ToSlash(path)}).String()
+
+	resolver := NewResolver(config.SecretsConfig{})
+	env, values, err := resolver.ResolveEnv(map[string]string{
+		"A": "env:ORCH_TEST_TOKEN",
+		"B": fileRef,
+		"C": "plain",
+	})
+	if err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+	if env["A"] != "env-secret" || env["B"] != "file-secret" || env["C"] != "plain" {
+		t.Fatalf("unexpected env: %v", env)
+	}
+	if len(values) != 2 {
+		t.Fatalf("expected 2 secret values, got %v", values)
+	}
+}
+
+func TestResolveMissingEnv(t *testing.T) {
+	resolver := NewResolver(config.SecretsConfig{})
+	if _, err := resolver.Resolve("env:ORCH_TEST_UNSET_VARIABLE"); err == nil {
+		t.Fatalf("expected error for unset variable")
+	}
+}
+
+func TestSecretsFileRoundTrip(t *testing.T) {
+	encoded, err := GenerateKey()
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	t.Setenv("ORCH_TEST_KEY", encoded)
+	cfg := config.SecretsConfig{File: filepath.Join(t.TempDir(), "secrets.json"), KeyEnv: "ORCH_TEST_KEY"}
+
+	key, err := LoadKey(cfg)
+	if err != nil {
+		t.Fatalf("load key: %v", err)
+	}
+	if err := WriteFile(cfg.File, key, map[string]string{"deploy": "s3cr3t"}); err != nil {
+		t.Fatalf("write secrets: %v", err)
+	}
+
+	value, err := NewResolver(cfg).Resolve("secret:deploy")
+	if err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+	if value != "s3cr3t" {
+		t.Fatalf("expected s3cr3t, got %q", value)
+	}
+
+	other, _ := GenerateKey()
+	otherKey, _ := decodeKey(other)
+	if _, err := ReadFile(cfg.File, otherKey); err == nil {
+		t.Fatalf("expected wrong key to fail")
+	}
+}
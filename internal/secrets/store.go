@@ -0,0 +1,163 @@
+package secrets
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"orchastration/internal/config"
+)
+
+const (
+	fileVersion = 1
+	keySize     = 32
+)
+
+// sealedFile is the on-disk layout of the secrets file. The ciphertext is
+// an AES-256-GCM sealed JSON object mapping secret names to values.
+type sealedFile struct {
+	Version    int    `json:"version"`
+	Nonce      string `json:"nonce"`
+	Ciphertext string `json:"ciphertext"`
+}
+
+// GenerateKey returns a new random key encoded for use in key_env or
+// key_file.
+func GenerateKey() (string, error) {
+	key := make([]byte, keySize)
+	if _, err := rand.Read(key); err != nil {
+		return "", fmt.Errorf("generate key: %w", err)
+	}
+	return base64.StdEncoding.EncodeToString(key), nil
+}
+
+// LoadKey reads the secrets file key from key_file when set, otherwise
+// from the key_env variable.
+func LoadKey(cfg config.SecretsConfig) ([]byte, error) {
+	var encoded string
+	if cfg.KeyFile != "" {
+		data, err := os.ReadFile(cfg.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("read key file: %w", err)
+		}
+		encoded = string(data)
+	} else {
+		name := cfg.KeyEnv
+		if name == "" {
+			name = DefaultKeyEnv
+		}
+		encoded = os.Getenv(name)
+		if encoded == "" {
+			return nil, fmt.Errorf("secrets key not set: %s", name)
+		}
+	}
+	return decodeKey(strings.TrimSpace(encoded))
+}
+
+func decodeKey(encoded string) ([]byte, error) {
+	if key, err := base64.StdEncoding.DecodeString(encoded); err == nil && len(key) == keySize {
+		return key, nil
+	}
+	if key, err := hex.DecodeString(encoded); err == nil && len(key) == keySize {
+		return key, nil
+	}
+	return nil, fmt.Errorf("secrets key must be %d bytes in base64 or hex", keySize)
+}
+
+// ReadFile decrypts the secrets file. A missing file holds no secrets.
+func ReadFile(path string, key []byte) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return map[string]string{}, nil
+		}
+		return nil, fmt.Errorf("read secrets file: %w", err)
+	}
+
+	var sealed sealedFile
+	if err := json.Unmarshal(data, &sealed); err != nil {
+		return nil, fmt.Errorf("parse secrets file: %w", err)
+	}
+	if sealed.Version != fileVersion {
+		return nil, fmt.Errorf("unsupported secrets file version: %d", sealed.Version)
+	}
+	nonce, err := base64.StdEncoding.DecodeString(sealed.Nonce)
+	if err != nil {
+		return nil, fmt.Errorf("parse secrets file: %w", err)
+	}
+	ciphertext, err := base64.StdEncoding.DecodeString(sealed.Ciphertext)
+	if err != nil {
+		return nil, fmt.Errorf("parse secrets file: %w", err)
+	}
+
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(nonce) != aead.NonceSize() {
+		return nil, errors.New("parse secrets file: invalid nonce")
+	}
+	plaintext, err := aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return nil, errors.New("decrypt secrets file: wrong key or corrupted file")
+	}
+
+	values := make(map[string]string)
+	if err := json.Unmarshal(plaintext, &values); err != nil {
+		return nil, fmt.Errorf("parse secrets file: %w", err)
+	}
+	return values, nil
+}
+
+// WriteFile encrypts the secrets and writes them with owner-only access.
+func WriteFile(path string, key []byte, values map[string]string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("create secrets dir: %w", err)
+	}
+
+	plaintext, err := json.Marshal(values)
+	if err != nil {
+		return fmt.Errorf("marshal secrets: %w", err)
+	}
+	aead, err := newAEAD(key)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return fmt.Errorf("generate nonce: %w", err)
+	}
+
+	data, err := json.MarshalIndent(sealedFile{
+		Version:    fileVersion,
+		Nonce:      base64.StdEncoding.EncodeToString(nonce),
+		Ciphertext: base64.StdEncoding.EncodeToString(aead.Seal(nil, nonce, plaintext, nil)),
+	}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal secrets file: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return fmt.Errorf("write secrets file: %w", err)
+	}
+	return nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("init cipher: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("init cipher: %w", err)
+	}
+	return aead, nil
+}
@@ -0,0 +1,10 @@
+package state
+
+// Artifact is a file captured from a job's working directory after a run.
+// Path is relative to the run's artifacts directory; Digest is prefixed
+// with the hash algorithm, as in "sha256:<hex>".
+type Artifact struct {
+	Path   string `json:"path"`
+	Size   int64  `json:"size_bytes"`
+	Digest string `json:"digest"`
+}
@@ -0,0 +1,38 @@
+package state
+
+import "time"
+
+type Attempt struct {
+	Number     int    `json:"number"`
+	StartTime  string `json:"start_time"`
+	EndTime    string `json:"end_time"`
+	DurationMs int64  `json:"duration_ms"`
+	ExitCode   int    `json:"exit_code"`
+	Error      string `json:"error,omitempty"`
+}
+
+func NewAttempt(number int, start time.Time, end time.Time, exitCode int, err error) Attempt {
+	attempt := Attempt{
+		Number:     number,
+		StartTime:  start.Format(time.RFC3339),
+		EndTime:    end.Format(time.RFC3339),
+		DurationMs: end.Sub(start).Milliseconds(),
+		ExitCode:   exitCode,
+	}
+	if err != nil {
+		attempt.Error = err.Error()
+	}
+	return attempt
+}
+
+func redactAttempts(attempts []Attempt, mask func(string) string) []Attempt {
+	if attempts == nil {
+		return nil
+	}
+	redacted := make([]Attempt, len(attempts))
+	for i, attempt := range attempts {
+		attempt.Error = mask(attempt.Error)
+		redacted[i] = attempt
+	}
+	return redacted
+}
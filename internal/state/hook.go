@@ -0,0 +1,44 @@
+package state
+
+import "time"
+
+// HookRun records one lifecycle hook command run alongside a job or task.
+type HookRun struct {
+	Stage      string `json:"stage"`
+	Command    string `json:"command"`
+	StartTime  string `json:"start_time"`
+	EndTime    string `json:"end_time"`
+	DurationMs int64  `json:"duration_ms"`
+	ExitCode   int    `json:"exit_code"`
+	Status     string `json:"status"`
+	Error      string `json:"error,omitempty"`
+}
+
+func NewHookRun(stage string, command string, start time.Time, end time.Time, exitCode int, status string, err error) HookRun {
+	run := HookRun{
+		Stage:      stage,
+		Command:    command,
+		StartTime:  start.Format(time.RFC3339),
+		EndTime:    end.Format(time.RFC3339),
+		DurationMs: end.Sub(start).Milliseconds(),
+		ExitCode:   exitCode,
+		Status:     status,
+	}
+	if err != nil {
+		run.Error = err.Error()
+	}
+	return run
+}
+
+func redactHooks(hooks []HookRun, mask func(string) string) []HookRun {
+	if hooks == nil {
+		return nil
+	}
+	redacted := make([]HookRun, len(hooks))
+	for i, hook := range hooks {
+		hook.Command = mask(hook.Command)
+		hook.Error = mask(hook.Error)
+		redacted[i] = hook
+	}
+	return redacted
+}
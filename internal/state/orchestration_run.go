@@ -17,12 +17,13 @@ type AgentRunRecord struct {
 }
 
 type OrchestrationRunRecord struct {
-	Orchestration string           `json:"orchestration"`
-	Agents        []AgentRunRecord `json:"agents"`
-	StartTime     string           `json:"start_time"`
-	EndTime       string           `json:"end_time"`
-	DurationMs    int64            `json:"duration_ms"`
-	Status        string           `json:"status"`
+	RunID         string            `json:"run_id,omitempty"`
+	Orchestration string            `json:"orchestration"`
+	Agents        []AgentRunRecord  `json:"agents"`
+	StartTime     string            `json:"start_time"`
+	EndTime       string            `json:"end_time"`
+	DurationMs    int64             `json:"duration_ms"`
+	Status        string            `json:"status"`
 	Context       map[string]string `json:"context,omitempty"`
 }
 
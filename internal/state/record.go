@@ -5,18 +5,65 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
+
+	"orchastration/internal/runid"
+)
+
+const (
+	// StatusRunning marks the record written when a run starts; it is
+	// replaced by the final record when the run ends.
+	StatusRunning = "running"
+	// StatusAbandoned marks running records whose process is gone.
+	StatusAbandoned = "abandoned"
+	// StatusCached marks runs skipped because their inputs matched the last
+	// successful run.
+	StatusCached = "cached"
 )
 
 type Record struct {
-	JobName    string `json:"job_name"`
-	StartTime  string `json:"start_time"`
-	EndTime    string `json:"end_time"`
-	DurationMs int64  `json:"duration_ms"`
-	ExitCode   int    `json:"exit_code"`
-	StdoutPath string `json:"stdout_path"`
-	StderrPath string `json:"stderr_path"`
-	OS         string `json:"os"`
-	Version    string `json:"binary_version"`
+	RunID        string            `json:"run_id,omitempty"`
+	JobName      string            `json:"job_name"`
+	StartTime    string            `json:"start_time"`
+	EndTime      string            `json:"end_time"`
+	DurationMs   int64             `json:"duration_ms"`
+	ExitCode     int               `json:"exit_code"`
+	Status       string            `json:"status,omitempty"`
+	Outcome      string            `json:"outcome,omitempty"`
+	StdoutPath   string            `json:"stdout_path"`
+	StderrPath   string            `json:"stderr_path"`
+	LogPath      string            `json:"log_path,omitempty"`
+	OS           string            `json:"os"`
+	Version      string            `json:"binary_version"`
+	Attempts     []Attempt         `json:"attempts,omitempty"`
+	Params       map[string]string `json:"params,omitempty"`
+	LimitsHit    []string          `json:"limits_hit,omitempty"`
+	Matrix       map[string]string `json:"matrix,omitempty"`
+	ArtifactsDir string            `json:"artifacts_dir,omitempty"`
+	Artifacts    []Artifact        `json:"artifacts,omitempty"`
+	Hooks        []HookRun         `json:"hooks,omitempty"`
+	PID          int               `json:"pid,omitempty"`
+	Host         string            `json:"host,omitempty"`
+	Heartbeat    string            `json:"heartbeat,omitempty"`
+	InputsDigest string            `json:"inputs_digest,omitempty"`
+	CachedFrom   string            `json:"cached_from,omitempty"`
+	Workspace    string            `json:"workspace,omitempty"`
+}
+
+// Redact returns a copy of the record with mask applied to free-form text
+// that may carry secret values.
+func (r Record) Redact(mask func(string) string) Record {
+	r.Attempts = redactAttempts(r.Attempts, mask)
+	r.Hooks = redactHooks(r.Hooks, mask)
+	if r.Params != nil {
+		params := make(map[string]string, len(r.Params))
+		for key, value := range r.Params {
+			params[key] = mask(value)
+		}
+		r.Params = params
+	}
+	return r
 }
 
 func WriteRecord(path string, record Record) error {
@@ -29,7 +76,25 @@ func WriteRecord(path string, record Record) error {
 		return fmt.Errorf("marshal record: %w", err)
 	}
 
-	if err := os.WriteFile(path, data, 0o644); err != nil {
+	// Records of running jobs are rewritten while others may read them, and
+	// parallel matrix cells all write last.json, so replace the file rather
+	// than truncating it.
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("write record: %w", err)
+	}
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(tmp.Name(), 0o644)
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
 		return fmt.Errorf("write record: %w", err)
 	}
 	return nil
@@ -46,3 +111,42 @@ func ReadRecord(path string) (Record, error) {
 	}
 	return record, nil
 }
+
+// RecordEntry pairs a job record with the run ID taken from its file name.
+type RecordEntry struct {
+	ID     string
+	Path   string
+	Record Record
+}
+
+// ListRecords reads every job record in a run directory, oldest first.
+// last.json and records written by other run types are skipped.
+func ListRecords(dir string) ([]RecordEntry, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	records := make([]RecordEntry, 0, len(entries))
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || filepath.Ext(name) != ".json" || name == "last.json" {
+			continue
+		}
+		path := filepath.Join(dir, name)
+		record, err := ReadRecord(path)
+		if err != nil || record.JobName == "" {
+			continue
+		}
+		records = append(records, RecordEntry{
+			ID:     strings.TrimSuffix(name, ".json"),
+			Path:   path,
+			Record: record,
+		})
+	}
+
+	sort.SliceStable(records, func(i, j int) bool {
+		return runid.Less(records[i].ID, records[j].ID)
+	})
+	return records, nil
+}
@@ -0,0 +1,49 @@
+package state
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+type ScheduleEntry struct {
+	JobName   string `json:"job_name"`
+	Schedule  string `json:"schedule"`
+	Timezone  string `json:"timezone,omitempty"`
+	LastFire  string `json:"last_fire,omitempty"`
+	NextFire  string `json:"next_fire,omitempty"`
+	LastError string `json:"last_error,omitempty"`
+}
+
+type ScheduleState struct {
+	Jobs map[string]ScheduleEntry `json:"jobs"`
+}
+
+func WriteSchedule(path string, schedule ScheduleState) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("create schedule dir: %w", err)
+	}
+
+	data, err := json.MarshalIndent(schedule, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal schedule: %w", err)
+	}
+
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("write schedule: %w", err)
+	}
+	return nil
+}
+
+func ReadSchedule(path string) (ScheduleState, error) {
+	var schedule ScheduleState
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return schedule, err
+	}
+	if err := json.Unmarshal(data, &schedule); err != nil {
+		return schedule, fmt.Errorf("parse schedule: %w", err)
+	}
+	return schedule, nil
+}
@@ -2,20 +2,42 @@ package state
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
+
+	"orchastration/internal/runid"
 )
 
 type TaskRunRecord struct {
-	TaskName   string `json:"task_name"`
-	Action     string `json:"action"`
-	StartTime  string `json:"start_time"`
-	EndTime    string `json:"end_time"`
-	DurationMs int64  `json:"duration_ms"`
-	Status     string `json:"status"`
-	ExitCode   int    `json:"exit_code"`
-	Message    string `json:"message,omitempty"`
+	RunID        string    `json:"run_id,omitempty"`
+	TaskName     string    `json:"task_name"`
+	Action       string    `json:"action"`
+	StartTime    string    `json:"start_time"`
+	EndTime      string    `json:"end_time"`
+	DurationMs   int64     `json:"duration_ms"`
+	Status       string    `json:"status"`
+	ExitCode     int       `json:"exit_code"`
+	RunStatus    string    `json:"run_status,omitempty"`
+	Outcome      string    `json:"outcome,omitempty"`
+	Message      string    `json:"message,omitempty"`
+	Attempts     []Attempt `json:"attempts,omitempty"`
+	LimitsHit    []string  `json:"limits_hit,omitempty"`
+	Hooks        []HookRun `json:"hooks,omitempty"`
+	InputsDigest string    `json:"inputs_digest,omitempty"`
+	CachedFrom   string    `json:"cached_from,omitempty"`
+}
+
+// Redact returns a copy of the record with mask applied to free-form text
+// that may carry secret values.
+func (r TaskRunRecord) Redact(mask func(string) string) TaskRunRecord {
+	r.Message = mask(r.Message)
+	r.Attempts = redactAttempts(r.Attempts, mask)
+	r.Hooks = redactHooks(r.Hooks, mask)
+	return r
 }
 
 func WriteTaskRun(path string, record TaskRunRecord) error {
@@ -33,3 +55,81 @@ func WriteTaskRun(path string, record TaskRunRecord) error {
 	}
 	return nil
 }
+
+func ReadTaskRun(path string) (TaskRunRecord, error) {
+	var record TaskRunRecord
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return record, err
+	}
+	if err := json.Unmarshal(data, &record); err != nil {
+		return record, fmt.Errorf("parse task run: %w", err)
+	}
+	return record, nil
+}
+
+// TaskRunEntry pairs a task run record with the run ID taken from its file
+// name.
+type TaskRunEntry struct {
+	ID     string
+	Path   string
+	Record TaskRunRecord
+}
+
+// ListTaskRuns reads every task run record in a directory, oldest first.
+func ListTaskRuns(dir string) ([]TaskRunEntry, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	records := make([]TaskRunEntry, 0, len(entries))
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || filepath.Ext(name) != ".json" {
+			continue
+		}
+		path := filepath.Join(dir, name)
+		record, err := ReadTaskRun(path)
+		if err != nil || record.TaskName == "" {
+			continue
+		}
+		records = append(records, TaskRunEntry{
+			ID:     strings.TrimSuffix(name, ".json"),
+			Path:   path,
+			Record: record,
+		})
+	}
+
+	sort.SliceStable(records, func(i, j int) bool {
+		return runid.Less(records[i].ID, records[j].ID)
+	})
+	return records, nil
+}
+
+// ListTaskRunHistory reads the runs of a task, oldest first. Besides
+// task-runs/<task>/ it reads runs/<task>/, where versions before task-runs/
+// existed wrote them; job records there have no task name and are skipped.
+func ListTaskRunHistory(stateDir, task string) ([]TaskRunEntry, error) {
+	var records []TaskRunEntry
+	found := false
+	for _, dir := range []string{filepath.Join(stateDir, "task-runs", task), filepath.Join(stateDir, "runs", task)} {
+		entries, err := ListTaskRuns(dir)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		found = true
+		records = append(records, entries...)
+	}
+	if !found {
+		return nil, fmt.Errorf("no runs recorded for task %s: %w", task, os.ErrNotExist)
+	}
+
+	sort.SliceStable(records, func(i, j int) bool {
+		return runid.Less(records[i].ID, records[j].ID)
+	})
+	return records, nil
+}
@@ -0,0 +1,32 @@
+package state
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestListTaskRunHistoryReadsLegacyRuns(t *testing.T) {
+	stateDir := t.TempDir()
+	writes := map[string]TaskRunRecord{
+		filepath.Join("runs", "build", "20250101T020000Z.json"):                {TaskName: "build", Action: "build.run"},
+		filepath.Join("runs", "build", "20250101T030000Z.json"):                {Action: "job.run"},
+		filepath.Join("task-runs", "build", "01JNBX3Q8ZK4T6V2W9YHDM5RCE.json"): {TaskName: "build", Action: "build.run"},
+	}
+	for path, record := range writes {
+		if err := WriteTaskRun(filepath.Join(stateDir, path), record); err != nil {
+			t.Fatalf("WriteTaskRun: %v", err)
+		}
+	}
+
+	entries, err := ListTaskRunHistory(stateDir, "build")
+	if err != nil {
+		t.Fatalf("ListTaskRunHistory: %v", err)
+	}
+	if len(entries) != 2 || entries[0].ID != "20250101T020000Z" || entries[1].ID != "01JNBX3Q8ZK4T6V2W9YHDM5RCE" {
+		t.Fatalf("unexpected entries: %+v", entries)
+	}
+
+	if _, err := ListTaskRunHistory(stateDir, "docs"); err == nil {
+		t.Fatalf("expected an error for a task with no runs")
+	}
+}
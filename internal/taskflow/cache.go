@@ -0,0 +1,80 @@
+package taskflow
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"orchastration/internal/config"
+	"orchastration/internal/digest"
+	"orchastration/internal/logging"
+	"orchastration/internal/notify"
+	"orchastration/internal/outcome"
+	"orchastration/internal/runid"
+	"orchastration/internal/runner"
+	"orchastration/internal/secrets"
+	"orchastration/internal/state"
+)
+
+// taskInputsDigest digests the task's inputs together with its command and
+// configured environment.
+func taskInputsDigest(taskCfg config.TaskConfig, algorithm string) (string, error) {
+	return digest.Inputs(digest.Spec{
+		Dir:      taskCfg.WorkingDir,
+		Patterns: taskCfg.Inputs,
+		Command:  taskCfg.Command,
+		Env:      taskCfg.Env,
+		EnvFiles: taskCfg.EnvFiles,
+	}, algorithm)
+}
+
+// findCachedBuild returns the last successful build of the task when its
+// inputs digest matches and the task's outputs still exist.
+func findCachedBuild(stateDir string, name string, taskCfg config.TaskConfig, inputsDigest string) (state.TaskRunEntry, bool) {
+	entries, err := state.ListTaskRunHistory(stateDir, name)
+	if err != nil {
+		return state.TaskRunEntry{}, false
+	}
+	for i := len(entries) - 1; i >= 0; i-- {
+		entry := entries[i]
+		if entry.Record.Action != "build.run" {
+			continue
+		}
+		if entry.Record.RunStatus != runner.StatusSuccess && entry.Record.RunStatus != state.StatusCached {
+			continue
+		}
+		if entry.Record.InputsDigest != inputsDigest || !digest.Exist(taskCfg.Outputs, taskCfg.WorkingDir) {
+			return state.TaskRunEntry{}, false
+		}
+		return entry, true
+	}
+	return state.TaskRunEntry{}, false
+}
+
+// recordCachedBuild records a build skipped because its inputs match source.
+func recordCachedBuild(name string, cfg config.Config, taskCfg config.TaskConfig, source state.TaskRunEntry, inputsDigest string, resolver *secrets.Resolver, logger *logging.Logger, stateDir string, w io.Writer) (int, error) {
+	cachedFrom := source.Record.CachedFrom
+	if cachedFrom == "" {
+		cachedFrom = source.ID
+	}
+
+	now := time.Now().UTC()
+	if err := UpdateTaskState(stateDir, name, taskCfg, "done", now); err != nil {
+		return 2, err
+	}
+	record := newTaskRunRecord(name, "build.run", now, now, "done", 0, "inputs unchanged")
+	record.RunID = runid.At(now)
+	record.RunStatus = state.StatusCached
+	record.Outcome = outcome.Success
+	record.InputsDigest = inputsDigest
+	record.CachedFrom = cachedFrom
+	if err := writeTaskRunRecord(stateDir, now, record); err != nil {
+		logger.Error("failed to write build run", "task", name, "error", err)
+		return 2, err
+	}
+	logger.Info("task inputs unchanged", "task", name, "cached_from", cachedFrom)
+	notify.New(cfg.Notifications, resolver, logger).Task(record)
+
+	fmt.Fprintf(w, "task=%s exit=0 status=done outcome=%s cached_from=%s\n", name, outcome.Success, cachedFrom)
+	return 0, nil
+}
@@ -1,18 +1,27 @@
 package taskflow
 
 import (
-	"context"
 	"errors"
 	"fmt"
 	"io"
 	"os"
 	"os/exec"
+	"os/signal"
 	"path/filepath"
 	"strings"
+	"syscall"
 	"time"
 
 	"orchastration/internal/config"
+	"orchastration/internal/environ"
+	"orchastration/internal/hooks"
 	"orchastration/internal/logging"
+	"orchastration/internal/notify"
+	"orchastration/internal/outcome"
+	"orchastration/internal/retry"
+	"orchastration/internal/runid"
+	"orchastration/internal/runner"
+	"orchastration/internal/secrets"
 	"orchastration/internal/state"
 )
 
@@ -58,7 +67,13 @@ func PlanCreate(name string, cfg config.Config, logger *logging.Logger, stateDir
 	return 0, nil
 }
 
-func BuildRun(name string, cfg config.Config, logger *logging.Logger, stateDir string, w io.Writer) (int, error) {
+// BuildOptions carries per-invocation settings for BuildRun.
+type BuildOptions struct {
+	// Force runs the task even when its inputs are unchanged.
+	Force bool
+}
+
+func BuildRun(name string, cfg config.Config, logger *logging.Logger, stateDir string, w io.Writer, opts BuildOptions) (int, error) {
 	if w == nil {
 		w = io.Discard
 	}
@@ -70,41 +85,139 @@ func BuildRun(name string, cfg config.Config, logger *logging.Logger, stateDir s
 		return 2, err
 	}
 
+	policy, err := retry.NewPolicy(taskCfg.Retries, taskCfg.RetryBackoff, taskCfg.RetryOnExit)
+	if err != nil {
+		return 2, fmt.Errorf("task %s: %w", name, err)
+	}
+	exitPolicy, err := outcome.NewPolicy(taskCfg.SuccessExitCodes, taskCfg.WarningExitCodes, taskCfg.AllowFailure)
+	if err != nil {
+		return 2, fmt.Errorf("task %s: %w", name, err)
+	}
+	redactor := logger.Redactor()
+	resolver := secrets.NewResolver(cfg.Secrets)
+	env, err := taskEnv(taskCfg, resolver, redactor)
+	if err != nil {
+		return 2, fmt.Errorf("task %s env: %w", name, err)
+	}
+
+	var inputsDigest string
+	if len(taskCfg.Inputs) > 0 {
+		if inputsDigest, err = taskInputsDigest(taskCfg, cfg.Hash.Algorithm); err != nil {
+			logger.Warn("input digest failed", "task", name, "error", err)
+		} else if source, ok := findCachedBuild(stateDir, name, taskCfg, inputsDigest); ok && !opts.Force {
+			return recordCachedBuild(name, cfg, taskCfg, source, inputsDigest, resolver, logger, stateDir, w)
+		}
+	}
+
 	start := time.Now().UTC()
 	if err := UpdateTaskState(stateDir, name, taskCfg, "in_progress", start); err != nil {
 		return 2, err
 	}
 
-	ctx := context.Background()
-	cmd := exec.CommandContext(ctx, taskCfg.Command[0], taskCfg.Command[1:]...)
-	cmd.Dir = taskCfg.WorkingDir
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	cmd.Env = os.Environ()
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(signals)
+
+	stdout, stderr := redactor.Writer(os.Stdout), redactor.Writer(os.Stderr)
+	defer stdout.Close()
+	defer stderr.Close()
+
+	// Task output goes to the terminal, so hooks get no log paths.
+	hookRunner := hooks.Runner{
+		Hooks:   taskCfg.Hooks,
+		Dir:     taskCfg.WorkingDir,
+		Env:     env,
+		Signals: signals,
+		Grace:   time.Duration(taskCfg.KillGraceSeconds) * time.Second,
+		Stdout:  stdout,
+		Stderr:  stderr,
+	}
+	hookCtx := hooks.Context{Kind: "task", Name: name, RunID: runid.At(start)}
 
 	logger.Info("task build starting", "task", name, "command", strings.Join(taskCfg.Command, " "))
-	execErr := cmd.Run()
+	attempts := make([]state.Attempt, 0, 1)
+	var limitsHit []string
+	var execErr error
+	exitCode := 0
+	runStatus := runner.StatusSuccess
+	hookRuns, beforeErr := hookRunner.Run(hooks.Before, hookCtx)
+	if beforeErr != nil {
+		last := hookRuns[len(hookRuns)-1]
+		execErr, runStatus, exitCode = beforeErr, last.Status, last.ExitCode
+		logger.Error("task before hook failed", "task", name, "error", beforeErr)
+	}
+	for attempt := 1; beforeErr == nil; attempt++ {
+		attemptStart := time.Now().UTC()
+		result := runTaskCommand(taskCfg, env, signals, stdout, stderr)
+		attemptEnd := time.Now().UTC()
+		exitCode = exitCodeFromError(result.Err)
+		runStatus, execErr = exitPolicy.Apply(result.Status, exitCode, result.Err)
+		for _, hit := range result.LimitsHit {
+			if !containsString(limitsHit, hit) {
+				limitsHit = append(limitsHit, hit)
+			}
+		}
+		if len(result.LimitsHit) > 0 {
+			logger.Warn("task build hit resource limits", "task", name, "attempt", attempt, "limits", strings.Join(result.LimitsHit, ","))
+		}
+		attempts = append(attempts, state.NewAttempt(attempt, attemptStart, attemptEnd, exitCode, execErr))
+		if execErr == nil {
+			break
+		}
+
+		logger.Error("task build failed", "task", name, "attempt", attempt, "run_status", runStatus, "error", execErr)
+		if runStatus == runner.StatusCancelled || !policy.ShouldRetry(attempt, exitCode) {
+			break
+		}
+		delay := policy.Backoff(attempt)
+		logger.Warn("task build retrying", "task", name, "attempt", attempt+1, "delay_ms", delay.Milliseconds())
+		if err := runner.Sleep(delay, signals); err != nil {
+			execErr, runStatus = fmt.Errorf("%w: %w", err, execErr), runner.StatusCancelled
+			logger.Error("task build cancelled", "task", name, "error", err)
+			break
+		}
+	}
 	end := time.Now().UTC()
 
-	exitCode := exitCodeFromError(execErr)
+	runOutcome := exitPolicy.Outcome(runStatus, exitCode)
+	hookCtx.Status, hookCtx.Outcome, hookCtx.ExitCode = runStatus, runOutcome, exitCode
+	hookCtx.DurationMs = end.Sub(start).Milliseconds()
+	afterRuns, err := hookRunner.After(hookCtx)
+	hookRuns = append(hookRuns, afterRuns...)
+	if err != nil {
+		logger.Warn("task hook failed", "task", name, "error", err)
+	}
 	status := "done"
 	message := "completed"
 	if execErr != nil {
-		status = "in_progress"
 		message = execErr.Error()
-		logger.Error("task build failed", "task", name, "error", execErr)
+		if runOutcome == outcome.Failure {
+			status = "in_progress"
+		} else {
+			logger.Warn("task build failure allowed", "task", name, "error", execErr)
+		}
 	}
 
 	if err := UpdateTaskState(stateDir, name, taskCfg, status, end); err != nil {
 		return 2, err
 	}
-	if err := WriteTaskRun(stateDir, name, "build.run", start, end, status, exitCode, message); err != nil {
+	record := newTaskRunRecord(name, "build.run", start, end, status, exitCode, message)
+	record.RunID = hookCtx.RunID
+	record.RunStatus = runStatus
+	record.Outcome = runOutcome
+	record.Attempts = attempts
+	record.LimitsHit = limitsHit
+	record.Hooks = hookRuns
+	record.InputsDigest = inputsDigest
+	record = record.Redact(redactor.String)
+	if err := writeTaskRunRecord(stateDir, start, record); err != nil {
 		logger.Error("failed to write build run", "task", name, "error", err)
 		return 2, err
 	}
+	notify.New(cfg.Notifications, resolver, logger).Task(record)
 
-	fmt.Fprintf(w, "task=%s exit=%d status=%s\n", name, exitCode, status)
-	if execErr != nil {
+	fmt.Fprintf(w, "task=%s exit=%d status=%s outcome=%s\n", name, exitCode, status, runOutcome)
+	if runOutcome == outcome.Failure {
 		return 2, execErr
 	}
 	return 0, nil
@@ -176,9 +289,12 @@ func UpdateTaskState(stateDir string, name string, taskCfg config.TaskConfig, st
 }
 
 func WriteTaskRun(stateDir string, taskName string, action string, start time.Time, end time.Time, status string, exitCode int, message string) error {
-	timestamp := start.Format("20060102T150405Z")
-	runPath := filepath.Join(stateDir, "runs", taskName, timestamp+".json")
-	record := state.TaskRunRecord{
+	record := newTaskRunRecord(taskName, action, start, end, status, exitCode, message)
+	return writeTaskRunRecord(stateDir, start, record)
+}
+
+func newTaskRunRecord(taskName string, action string, start time.Time, end time.Time, status string, exitCode int, message string) state.TaskRunRecord {
+	return state.TaskRunRecord{
 		TaskName:   taskName,
 		Action:     action,
 		StartTime:  start.Format(time.RFC3339),
@@ -188,9 +304,51 @@ func WriteTaskRun(stateDir string, taskName string, action string, start time.Ti
 		ExitCode:   exitCode,
 		Message:    message,
 	}
+}
+
+// writeTaskRunRecord stores a task run under task-runs/, apart from the job
+// runs in runs/ so a job and a task with the same name never collide.
+func writeTaskRunRecord(stateDir string, start time.Time, record state.TaskRunRecord) error {
+	if record.RunID == "" {
+		record.RunID = runid.At(start)
+	}
+	runPath := filepath.Join(stateDir, "task-runs", record.TaskName, record.RunID+".json")
 	return state.WriteTaskRun(runPath, record)
 }
 
+func taskEnv(taskCfg config.TaskConfig, resolver *secrets.Resolver, redactor *logging.Redactor) ([]string, error) {
+	inherit, allow, err := environ.ParseInherit(taskCfg.InheritEnv)
+	if err != nil {
+		return nil, err
+	}
+	env, values, err := resolver.ResolveEnv(taskCfg.Env)
+	if err != nil {
+		return nil, err
+	}
+	redactor.Add(values...)
+	return environ.Build(os.Environ(), environ.Spec{
+		Inherit: inherit,
+		Allow:   allow,
+		Files:   taskCfg.EnvFiles,
+		BaseDir: taskCfg.WorkingDir,
+		Env:     env,
+	})
+}
+
+func runTaskCommand(taskCfg config.TaskConfig, env []string, signals <-chan os.Signal, stdout io.Writer, stderr io.Writer) runner.Result {
+	cmd := exec.Command(taskCfg.Command[0], taskCfg.Command[1:]...)
+	cmd.Dir = taskCfg.WorkingDir
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	cmd.Env = env
+	return runner.Run(cmd, runner.Options{
+		Grace:   time.Duration(taskCfg.KillGraceSeconds) * time.Second,
+		Signals: signals,
+		Limits:  taskCfg.Limits,
+		Sandbox: taskCfg.Sandbox,
+	})
+}
+
 func ValidateTaskConfig(name string, task config.TaskConfig) error {
 	if name == "" {
 		return errors.New("task name is required")
@@ -270,3 +428,12 @@ func exitCodeFromError(err error) int {
 	}
 	return 1
 }
+
+func containsString(values []string, target string) bool {
+	for _, value := range values {
+		if value == target {
+			return true
+		}
+	}
+	return false
+}